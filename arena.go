@@ -0,0 +1,50 @@
+package tail
+
+import "unsafe"
+
+// arena allocates line texts from shared chunks to reduce per-line allocations.
+// A chunk is never modified once a string refers to it, so the strings stay immutable,
+// and it is freed by the GC once no maintained line refers to it.
+type arena struct {
+	chunk []byte
+	size  int
+}
+
+// arenaString returns a copy of s allocated from a.
+// Texts larger than a quarter of the chunk size are allocated separately.
+func arenaString[S ~string | ~[]byte](a *arena, s S) string {
+	if len(s) == 0 {
+		return ""
+	}
+	if len(s) > a.size/4 {
+		return string(s)
+	}
+	if cap(a.chunk)-len(a.chunk) < len(s) {
+		a.chunk = make([]byte, 0, a.size)
+	}
+	start := len(a.chunk)
+	a.chunk = a.chunk[:start+len(s)]
+	copy(a.chunk[start:], s)
+	return unsafe.String(&a.chunk[start], len(s))
+}
+
+// lineText returns s as the text of a line, interning it or copying it into the arena if enabled.
+// With WithRedaction, s is returned as is, and storeText is applied once it is redacted,
+// so that no unredacted text is kept by the interner or the arena.
+func lineText[S ~string | ~[]byte](tb *TailBuffer, s S) string {
+	if len(tb.redaction) > 0 {
+		return string(s)
+	}
+	return storeText(tb, s)
+}
+
+// storeText returns s interned or copied into the arena if enabled.
+func storeText[S ~string | ~[]byte](tb *TailBuffer, s S) string {
+	if tb.interner != nil {
+		return internString(tb, s)
+	}
+	if tb.arena != nil {
+		return arenaString(tb.arena, s)
+	}
+	return string(s)
+}
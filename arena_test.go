@@ -0,0 +1,47 @@
+package tail
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTailBuffer_WithArena(t *testing.T) {
+	tw := New(3, WithArena(64))
+	writes := []string{"line1\n", "line2\nline3\nli", "ne4\n", strings.Repeat("a", 32) + "\n"}
+	for _, data := range writes {
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	expected := []string{"line3", "line4", strings.Repeat("a", 32)}
+	if result := tw.Lines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+
+	// Maintained lines are not affected by later writes filling the chunk
+	before := tw.Lines()
+	for i := 0; i < 100; i++ {
+		if _, err := fmt.Fprintf(tw, "line%d\n", i); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if !slices.Equal(before, expected) {
+		t.Errorf("expected %q, got %q", expected, before)
+	}
+	expected = []string{"line97", "line98", "line99"}
+	if result := tw.Lines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+func BenchmarkTailBuffer_WriteArena(b *testing.B) {
+	tw := New(1000, WithArena(64*1024))
+	data := []byte("This is a benchmark test line\n")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = tw.Write(data)
+	}
+}
@@ -0,0 +1,71 @@
+package tail
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Color is a foreground color of ANSI escape codes used by ColorString.
+type Color int
+
+// Colors, with the values of their ANSI escape codes.
+const (
+	ColorNone    Color = 0
+	ColorRed     Color = 31
+	ColorGreen   Color = 32
+	ColorYellow  Color = 33
+	ColorBlue    Color = 34
+	ColorMagenta Color = 35
+	ColorCyan    Color = 36
+	ColorGray    Color = 90
+)
+
+// ColorString returns the maintained lines joined like String, with each line wrapped in the ANSI escape codes
+// of the color classify returns for it, for display in a terminal. ColorNone leaves the line as is.
+// The maintained lines are not modified. classify is called without the lock held.
+func (tb *TailBuffer) ColorString(classify func(line string) Color) string {
+	tb.mu.Lock()
+	ts := tb.textSnapshot()
+	tb.mu.Unlock()
+
+	return ts.join(classify)
+}
+
+// ClassifyLevel is a classifier for ColorString that colors lines by the log level they mention:
+// red for errors, yellow for warnings, green for info and gray for debug. It is case-insensitive,
+// and the most severe level found is used.
+func ClassifyLevel(line string) Color {
+	switch l := strings.ToLower(line); {
+	case mentionsError(l):
+		return ColorRed
+	case strings.Contains(l, "warn"):
+		return ColorYellow
+	case strings.Contains(l, "info"):
+		return ColorGreen
+	case strings.Contains(l, "debug"):
+		return ColorGray
+	default:
+		return ColorNone
+	}
+}
+
+// ClassifyErrors is a classifier for ColorString that colors only lines mentioning errors, in red.
+func ClassifyErrors(line string) Color {
+	if mentionsError(strings.ToLower(line)) {
+		return ColorRed
+	}
+	return ColorNone
+}
+
+// mentionsError reports whether the lowercase line mentions an error level.
+func mentionsError(line string) bool {
+	return strings.Contains(line, "error") || strings.Contains(line, "fatal") || strings.Contains(line, "panic")
+}
+
+// wrap wraps s in the ANSI escape codes of c.
+func (c Color) wrap(s string) string {
+	if c == ColorNone {
+		return s
+	}
+	return "\x1b[" + strconv.Itoa(int(c)) + "m" + s + "\x1b[0m"
+}
@@ -0,0 +1,54 @@
+package tail
+
+import "testing"
+
+func TestTailBuffer_ColorString(t *testing.T) {
+	tests := []struct {
+		name     string
+		classify func(line string) Color
+		input    string
+		expected string
+	}{
+		{
+			name:     "levels",
+			classify: ClassifyLevel,
+			input:    "ERROR failed\nWarning: slow\nlevel=info msg=ok\nplain\n",
+			expected: "\x1b[31mERROR failed\x1b[0m\n\x1b[33mWarning: slow\x1b[0m\n\x1b[32mlevel=info msg=ok\x1b[0m\nplain\n",
+		},
+		{
+			name:     "most severe level",
+			classify: ClassifyLevel,
+			input:    "info: panic recovered\n",
+			expected: "\x1b[31minfo: panic recovered\x1b[0m\n",
+		},
+		{
+			name:     "errors only",
+			classify: ClassifyErrors,
+			input:    "fatal: boom\nwarn\npen",
+			expected: "\x1b[31mfatal: boom\x1b[0m\nwarn\npen",
+		},
+		{
+			name:     "custom classifier",
+			classify: func(string) Color { return ColorCyan },
+			input:    "a\n",
+			expected: "\x1b[36ma\x1b[0m\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(5)
+			if _, err := tw.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result := tw.ColorString(tt.classify); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+			// The maintained lines are not colored
+			if result := tw.String(); result != tt.input {
+				t.Errorf("expected %q, got %q", tt.input, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package tail
+
+import (
+	"encoding/csv"
+	"strings"
+)
+
+// CSVRecords parses the maintained lines as CSV and returns the records.
+// The lines are rejoined with "\n" before parsing, so a quoted field containing a newline
+// is parsed as a single field as long as all of its lines are still maintained.
+// Each physical line is still counted against maxLines; if the oldest maintained line starts
+// in the middle of a quoted field, an error may be returned.
+func (tb *TailBuffer) CSVRecords() ([][]string, error) {
+	tb.mu.Lock()
+	snapshot := tb.snapshot()
+	tb.mu.Unlock()
+
+	texts := make([]string, len(snapshot))
+	for i, l := range snapshot {
+		texts[i] = l.text
+	}
+
+	r := csv.NewReader(strings.NewReader(strings.Join(texts, "\n")))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		records = [][]string{}
+	}
+	return records, nil
+}
@@ -0,0 +1,73 @@
+package tail
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTailBuffer_CSVRecords(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		input    string
+		expected [][]string
+		wantErr  bool
+	}{
+		{
+			name:  "basic",
+			limit: 2,
+			input: "a,b,c\n1,2,3\n4,5,6\n",
+			expected: [][]string{
+				{"1", "2", "3"},
+				{"4", "5", "6"},
+			},
+		},
+		{
+			name:  "quoted fields",
+			limit: 3,
+			input: "1,\"hello, world\",3\n4,\"multi\nline\",6\n",
+			expected: [][]string{
+				{"1", "hello, world", "3"},
+				{"4", "multi\nline", "6"},
+			},
+		},
+		{
+			name:  "pending line",
+			limit: 3,
+			input: "1,2\n3,4",
+			expected: [][]string{
+				{"1", "2"},
+				{"3", "4"},
+			},
+		},
+		{
+			name:    "quoted field cut by eviction",
+			limit:   2,
+			input:   "1,\"multi\nline\nfield\",3\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(tt.limit)
+			if _, err := tw.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := tw.CSVRecords()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.EqualFunc(result, tt.expected, slices.Equal) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package tail
+
+import "strings"
+
+// diffEvictedMarker is the line DiffString writes in place of the lines completed and evicted since the snapshot.
+const diffEvictedMarker = "..."
+
+// DiffString returns the maintained lines each followed by the delimiter, with the lines completed since prev,
+// taken by Snapshot, prefixed with "+" and the other lines prefixed with " ", like a unified diff.
+// If lines completed since prev have already been evicted, the line "..." is written first.
+// The pending line is marked as new unless it is the same as the last line of prev.
+//
+// The lines are told apart by the number of completed lines in prev.Stats.TotalLines,
+// so lines dropped or joined by options such as WithSeenFilter or WithContinuation may be misattributed.
+func (tb *TailBuffer) DiffString(prev Snapshot) string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	delim := tb.primaryDelimiter()
+	added := int(tb.totalLines - prev.Stats.TotalLines)
+	unchanged := len(tb.lines) - added
+
+	var sb strings.Builder
+	if unchanged < 0 {
+		sb.WriteString(diffEvictedMarker)
+		sb.WriteString(delim)
+	}
+	// A pending continuation line changes the last line it is joined to
+	joined := tb.buffer.Len() > 0 && !tb.pendingHidden() && tb.pendingContinues()
+	for i, l := range tb.lines {
+		text, same := l.text, i < unchanged
+		if joined && i == len(tb.lines)-1 {
+			text += "\n" + tb.pendingText()
+			same = same && len(prev.Lines) > 0 && prev.Lines[len(prev.Lines)-1] == text
+		}
+		if same {
+			sb.WriteString(" ")
+		} else {
+			sb.WriteString("+")
+		}
+		sb.WriteString(text)
+		sb.WriteString(delim)
+	}
+
+	if tb.pendingSeparate() {
+		pending := tb.pendingText()
+		if added == 0 && len(prev.Lines) > 0 && prev.Lines[len(prev.Lines)-1] == pending {
+			sb.WriteString(" ")
+		} else {
+			sb.WriteString("+")
+		}
+		sb.WriteString(pending)
+		sb.WriteString(delim)
+	}
+	return sb.String()
+}
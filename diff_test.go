@@ -0,0 +1,60 @@
+package tail
+
+import "testing"
+
+func TestTailBuffer_DiffString(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected string
+	}{
+		{
+			name:     "new lines",
+			before:   "line1\nline2\n",
+			after:    "line3\n",
+			expected: " line1\n line2\n+line3\n",
+		},
+		{
+			name:     "no change",
+			before:   "line1\nline2\npen",
+			after:    "",
+			expected: " line1\n line2\n pen\n",
+		},
+		{
+			name:     "pending line completed",
+			before:   "line1\npen",
+			after:    "ding\nnext",
+			expected: " line1\n+pending\n+next\n",
+		},
+		{
+			name:     "unchanged lines evicted",
+			before:   "line1\nline2\nline3\n",
+			after:    "line4\nline5\n",
+			expected: " line3\n+line4\n+line5\n",
+		},
+		{
+			name:     "new lines evicted",
+			before:   "line1\n",
+			after:    "line2\nline3\nline4\nline5\n",
+			expected: "...\n+line3\n+line4\n+line5\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(3)
+			if _, err := tw.Write([]byte(tt.before)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			prev := tw.Snapshot()
+			if _, err := tw.Write([]byte(tt.after)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result := tw.DiffString(prev); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package tail
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// encodingVersion is the version of the format written by Encode.
+// Version 1 lacks the sequence IDs and the total number of lines.
+const encodingVersion = 2
+
+// ErrInvalidEncoding is returned by Decode when the data is truncated or malformed.
+var ErrInvalidEncoding = errors.New("tail: invalid encoding")
+
+// Encode returns the maintained lines and the pending line in a compact binary format
+// that can be restored with Decode, e.g. to persist snapshots of the buffer.
+// The format consists of a version byte, the number of lines as a varint, each line and its terminator
+// prefixed with their lengths as varints followed by its sequence ID as a varint, maxLines as a varint,
+// the length-prefixed pending line and the total number of lines written as a varint.
+// Options and the other counters are not encoded.
+//
+// It is much smaller than JSON: a line costs its length plus two bytes for lines shorter than 128 bytes,
+// without quoting, escaping or field names.
+func (tb *TailBuffer) Encode() []byte {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	size := 1 + 4*binary.MaxVarintLen64 + tb.buffer.Len()
+	for _, l := range tb.lines {
+		size += 3*binary.MaxVarintLen64 + len(l.text) + len(l.term)
+	}
+	b := make([]byte, 0, size)
+	b = append(b, encodingVersion)
+	b = binary.AppendUvarint(b, uint64(len(tb.lines)))
+	for i, l := range tb.lines {
+		b = appendString(b, l.text)
+		b = appendString(b, l.term)
+		b = binary.AppendUvarint(b, tb.meta.seq(i))
+	}
+	b = binary.AppendUvarint(b, uint64(tb.maxLines))
+	b = appendString(b, tb.pendingText())
+	b = binary.AppendUvarint(b, uint64(tb.totalLines))
+	return b
+}
+
+// Decode creates a new TailBuffer from data returned by Encode, applying opts.
+// The lines are maintained as is, without being split or processed by options.
+// Data of the previous version is decoded with no sequence IDs.
+func Decode(b []byte, opts ...Option) (*TailBuffer, error) {
+	if len(b) == 0 {
+		return nil, ErrInvalidEncoding
+	}
+	version := b[0]
+	if version != 1 && version != encodingVersion {
+		return nil, fmt.Errorf("tail: unsupported encoding version %d", version)
+	}
+	r := encodingReader{b: b[1:]}
+
+	count := r.uvarint()
+	// Each line takes at least two bytes
+	if count > uint64(len(r.b)/2) {
+		return nil, ErrInvalidEncoding
+	}
+	lines := make([]line, count)
+	for i := range lines {
+		lines[i].text = r.string()
+		lines[i].term = r.string()
+		if version > 1 {
+			lines[i].seq = r.uvarint()
+		}
+	}
+	maxLines := r.uvarint()
+	pending := r.string()
+	var totalLines uint64
+	if version > 1 {
+		totalLines = r.uvarint()
+	}
+	if r.err != nil || len(r.b) != 0 || maxLines > math.MaxInt || totalLines > math.MaxInt64 {
+		return nil, ErrInvalidEncoding
+	}
+	if maxLines == 0 && len(lines) > 0 || maxLines > 0 && uint64(len(lines)) > maxLines {
+		return nil, ErrInvalidEncoding
+	}
+
+	tb := New(int(maxLines), opts...)
+	for i := range lines {
+		tb.pushLine(&lines[i])
+	}
+	tb.buffer.WriteString(pending)
+	tb.totalLines = int64(totalLines)
+	return tb, nil
+}
+
+// appendString appends s prefixed with its length as a varint to b.
+func appendString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// encodingReader reads the values written by Encode, recording the first error.
+type encodingReader struct {
+	b   []byte
+	err error
+}
+
+func (r *encodingReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		r.err = ErrInvalidEncoding
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *encodingReader) string() string {
+	n := r.uvarint()
+	if r.err != nil {
+		return ""
+	}
+	if n > uint64(len(r.b)) {
+		r.err = ErrInvalidEncoding
+		return ""
+	}
+	s := string(r.b[:n])
+	r.b = r.b[n:]
+	return s
+}
@@ -0,0 +1,130 @@
+package tail
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTailBuffer_Encode(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		input string
+	}{
+		{
+			name:  "lines and pending line",
+			limit: 3,
+			input: "line1\nline2\r\nline3\nline4\npen",
+		},
+		{
+			name:  "no pending line",
+			limit: 2,
+			input: "line1\nline2\n",
+		},
+		{
+			name:  "empty",
+			limit: 5,
+			input: "",
+		},
+		{
+			name:  "no lines maintained",
+			limit: 0,
+			input: "line1\npen",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := New(tt.limit)
+			if _, err := tb.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			decoded, err := Decode(tb.Encode())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if expected, result := tb.Lines(), decoded.Lines(); !slices.Equal(result, expected) {
+				t.Errorf("expected %q, got %q", expected, result)
+			}
+			if expected, result := tb.RawBytes(), decoded.RawBytes(); !bytes.Equal(result, expected) {
+				t.Errorf("expected %q, got %q", expected, result)
+			}
+			if decoded.maxLines != tt.limit {
+				t.Errorf("expected maxLines %d, got %d", tt.limit, decoded.maxLines)
+			}
+
+			// Writing continues from the pending line
+			if _, err := decoded.Write([]byte("ding\n")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := tb.Write([]byte("ding\n")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if expected, result := tb.String(), decoded.String(); result != expected {
+				t.Errorf("expected %q, got %q", expected, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_Encode_SmallerThanJSON(t *testing.T) {
+	tb := New(100)
+	for range 100 {
+		if _, err := tb.Write([]byte("level=info msg=\"request handled\" status=200\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	j, err := json.Marshal(tb.Lines())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b := tb.Encode(); len(b) >= len(j) {
+		t.Errorf("expected less than %d bytes, got %d", len(j), len(b))
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	valid := New(3)
+	if _, err := valid.Write([]byte("line1\nline2\npen")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b := valid.Encode()
+
+	tests := []struct {
+		name    string
+		input   []byte
+		invalid bool
+	}{
+		{"empty", []byte{}, true},
+		{"unsupported version", append([]byte{3}, b[1:]...), false},
+		{"truncated", b[:len(b)-1], true},
+		{"trailing data", append(slices.Clone(b), 0), true},
+		{"more lines than maxLines", []byte{1, 2, 1, 'a', 1, '\n', 1, 'b', 1, '\n', 1, 0}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.input); err == nil {
+				t.Error("expected error, got nil")
+			} else if tt.invalid && !errors.Is(err, ErrInvalidEncoding) {
+				t.Errorf("expected %v, got %v", ErrInvalidEncoding, err)
+			}
+		})
+	}
+}
+
+func TestDecode_Version1(t *testing.T) {
+	// version 1, two lines, maxLines 3, pending line "pen"
+	b := []byte{1, 2, 1, 'a', 1, '\n', 1, 'b', 1, '\n', 3, 3, 'p', 'e', 'n'}
+	tb, err := Decode(b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected, result := []string{"a", "b", "pen"}, tb.Lines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
@@ -0,0 +1,92 @@
+package tail
+
+import "sync"
+
+// EvictionReason is the reason a line is evicted.
+type EvictionReason int
+
+const (
+	// EvictedByMaxLines is the reason for lines evicted by maxLines, or by the rules of WithSectionStart and WithSpanMarkers.
+	EvictedByMaxLines EvictionReason = iota
+	// EvictedByMaxRunes is the reason for lines evicted by WithMaxRunes.
+	EvictedByMaxRunes
+	// EvictedByMemoryCap is the reason for lines evicted by WithMemoryCap.
+	EvictedByMemoryCap
+)
+
+// String returns the name of the reason.
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictedByMaxLines:
+		return "max lines"
+	case EvictedByMaxRunes:
+		return "max runes"
+	case EvictedByMemoryCap:
+		return "memory cap"
+	default:
+		return "unknown"
+	}
+}
+
+// EvictionEvent describes a line evicted from the maintained lines.
+type EvictionEvent struct {
+	Line string
+	// Seq is the sequence ID of the line with WithSequenceIDs, or 0 otherwise.
+	Seq    uint64
+	Reason EvictionReason
+}
+
+// evictionNotifier queues eviction events to be delivered once the lock is released.
+type evictionNotifier struct {
+	fn func(ev EvictionEvent)
+	// spill is the files the evicted lines are appended to with WithFileRotation.
+	spill  *fileRotator
+	events []EvictionEvent
+	// mu serializes the deliveries so that the events are delivered in order.
+	mu sync.Mutex
+}
+
+// recordEvictions queues the events of the maintained lines from start to end about to be evicted for reason.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) recordEvictions(start, end int, reason EvictionReason) {
+	if tb.evictions == nil {
+		return
+	}
+	for i := start; i < end; i++ {
+		tb.evictions.events = append(tb.evictions.events, EvictionEvent{Line: tb.lines[i].text, Seq: tb.meta.seq(i), Reason: reason})
+	}
+}
+
+// notifier returns the eviction notifier, creating it if it does not exist yet.
+func (tb *TailBuffer) notifier() *evictionNotifier {
+	if tb.evictions == nil {
+		tb.evictions = &evictionNotifier{}
+	}
+	return tb.evictions
+}
+
+// deliverEvictions calls the callback set by WithEvictionCallback with the queued events,
+// and appends them to the files set by WithFileRotation.
+// It must be called without tb.mu held.
+func (tb *TailBuffer) deliverEvictions() {
+	n := tb.evictions
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	tb.mu.Lock()
+	events := n.events
+	n.events = nil
+	tb.mu.Unlock()
+
+	if n.fn != nil {
+		for _, ev := range events {
+			n.fn(ev)
+		}
+	}
+	if n.spill != nil {
+		n.spill.write(events)
+	}
+}
@@ -0,0 +1,73 @@
+package tail
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTailBuffer_WithEvictionCallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		opts     []Option
+		writes   []string
+		expected []EvictionEvent
+	}{
+		{
+			name:   "max lines",
+			limit:  2,
+			opts:   []Option{WithSequenceIDs()},
+			writes: []string{"line1\nline2\n", "line3\nline4\nline5\npen"},
+			expected: []EvictionEvent{
+				{Line: "line1", Seq: 1, Reason: EvictedByMaxLines},
+				{Line: "line2", Seq: 2, Reason: EvictedByMaxLines},
+				{Line: "line3", Seq: 3, Reason: EvictedByMaxLines},
+			},
+		},
+		{
+			name:   "max runes",
+			limit:  10,
+			opts:   []Option{WithMaxRunes(4)},
+			writes: []string{"ab\ncd\n", "ef\n"},
+			expected: []EvictionEvent{
+				{Line: "ab", Reason: EvictedByMaxRunes},
+			},
+		},
+		{
+			name:   "memory cap",
+			limit:  10,
+			opts:   []Option{WithMemoryCap(2 * (int(lineOverhead) + 6))},
+			writes: []string{"line1\nline2\n", "line3\n"},
+			expected: []EvictionEvent{
+				{Line: "line1", Reason: EvictedByMemoryCap},
+			},
+		},
+		{
+			name:     "no eviction",
+			limit:    0,
+			writes:   []string{"line1\nline2\n"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tw *TailBuffer
+			var events []EvictionEvent
+			tw = New(tt.limit, append(tt.opts, WithEvictionCallback(func(ev EvictionEvent) {
+				// The lock is released
+				_ = tw.Len()
+				events = append(events, ev)
+			}))...)
+			for _, data := range tt.writes {
+				if _, err := tw.Write([]byte(data)); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			if !slices.Equal(events, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, events)
+			}
+		})
+	}
+}
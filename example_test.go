@@ -3,6 +3,7 @@ package tail_test
 import (
 	"fmt"
 	"log"
+	"strconv"
 
 	"github.com/k1LoW/tail"
 )
@@ -85,3 +86,35 @@ func ExampleTailBuffer_asWriter() {
 	// Log entry 9
 	// Log entry 10
 }
+
+func ExampleReduce() {
+	tw := tail.New(3)
+
+	if _, err := tw.Write([]byte("10\n20\n30\n40\n")); err != nil {
+		log.Fatal(err)
+	}
+
+	// Sum the retained numbers
+	sum := tail.Reduce(tw, 0, func(acc int, line string) int {
+		n, _ := strconv.Atoi(line)
+		return acc + n
+	})
+	fmt.Println(sum)
+	// Output:
+	// 90
+}
+
+func ExampleTailWriter() {
+	// Depend on the interface to substitute fakes in tests
+	var tw tail.TailWriter = tail.New(2)
+
+	logger := log.New(tw, "", 0)
+	logger.Print("starting")
+	logger.Print("listening")
+	logger.Print("stopped")
+
+	fmt.Print(tw.String())
+	// Output:
+	// listening
+	// stopped
+}
@@ -0,0 +1,34 @@
+package tail
+
+import (
+	"context"
+	"os/exec"
+)
+
+// CaptureCommand runs cmd with its stdout and stderr combined into a new TailBuffer maintaining
+// the last maxLines lines, and returns the buffer once the command exits. If ctx is done before that,
+// the command is killed. The buffer holds the output written so far even if the command fails or is killed,
+// in which case the error of the command or ctx.Err() is returned along with it.
+// If the command starts subprocesses that keep the output open after it is killed, set cmd.WaitDelay
+// so that CaptureCommand does not wait for them.
+func CaptureCommand(ctx context.Context, maxLines int, cmd *exec.Cmd, opts ...Option) (*TailBuffer, error) {
+	tb := New(maxLines, opts...)
+	cmd.Stdout = tb
+	cmd.Stderr = tb
+	if err := cmd.Start(); err != nil {
+		return tb, err
+	}
+
+	waitDone := make(chan error, 1)
+	go func() {
+		waitDone <- cmd.Wait()
+	}()
+	select {
+	case err := <-waitDone:
+		return tb, err
+	case <-ctx.Done():
+		_ = cmd.Process.Kill()
+		<-waitDone
+		return tb, ctx.Err()
+	}
+}
@@ -0,0 +1,68 @@
+package tail
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCaptureCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+
+	tests := []struct {
+		name        string
+		script      string
+		timeout     time.Duration
+		expected    []string
+		expectedErr func(error) bool
+	}{
+		{
+			name:     "last lines of output",
+			script:   "for i in 1 2 3 4; do echo line$i; done",
+			timeout:  10 * time.Second,
+			expected: []string{"line2", "line3", "line4"},
+			expectedErr: func(err error) bool {
+				return err == nil
+			},
+		},
+		{
+			name:     "stderr and failure",
+			script:   "echo out; echo err >&2; exit 3",
+			timeout:  10 * time.Second,
+			expected: []string{"out", "err"},
+			expectedErr: func(err error) bool {
+				var exitErr *exec.ExitError
+				return errors.As(err, &exitErr) && exitErr.ExitCode() == 3
+			},
+		},
+		{
+			name:     "killed on timeout",
+			script:   "echo before; printf partial; exec sleep 10",
+			timeout:  200 * time.Millisecond,
+			expected: []string{"before", "partial"},
+			expectedErr: func(err error) bool {
+				return errors.Is(err, context.DeadlineExceeded)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			tb, err := CaptureCommand(ctx, 3, exec.Command("sh", "-c", tt.script))
+			if !tt.expectedErr(err) {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if result := tb.Lines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package tail
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+)
+
+// FairKeyedBuffer maintains the last lines of several sources, such as writers sharing a buffer,
+// in a bounded window shared fairly between the keys that lines are routed by.
+type FairKeyedBuffer struct {
+	tb         *TailBuffer
+	mu         sync.Mutex
+	totalLines int
+	keyFn      func(line string) string
+	// windows maps each key to its lines, the oldest first.
+	windows map[string][]fairLine
+	count   int
+	seq     uint64
+}
+
+// fairLine is a line maintained by FairKeyedBuffer with its order of completion.
+type fairLine struct {
+	seq  uint64
+	text string
+}
+
+// NewFairKeyed creates a new FairKeyedBuffer maintaining up to totalLines completed lines in total,
+// routed by the key returned by keyFn. opts configure how written data is split into lines, e.g. WithDelimiters.
+// A negative totalLines is treated as 0.
+//
+// The lines are shared with max-min fairness: when the window is full, the oldest line of the key
+// with the most lines is evicted, the key with the oldest line among them on a tie. So a quiet key keeps
+// all of its lines while it has fewer than its fair share, and a chatty key cannot starve the others out.
+// The shares adjust as keys come and go: a new key takes lines from the keys with the most lines,
+// and a key whose lines are all evicted leaves its share to the others.
+func NewFairKeyed(totalLines int, keyFn func(line string) string, opts ...Option) *FairKeyedBuffer {
+	fb := &FairKeyedBuffer{
+		tb:         New(1, opts...),
+		totalLines: max(totalLines, 0),
+		keyFn:      keyFn,
+		windows:    map[string][]fairLine{},
+	}
+
+	fb.tb.mu.Lock()
+	fb.tb.subscribe(fb)
+	fb.tb.mu.Unlock()
+	return fb
+}
+
+// Write implements the io.Writer interface.
+// It writes data and maintains each completed line under its key.
+func (fb *FairKeyedBuffer) Write(p []byte) (n int, err error) {
+	return fb.tb.Write(p)
+}
+
+// Lines returns the maintained lines of all keys in the order they were completed, the oldest first.
+func (fb *FairKeyedBuffer) Lines() []string {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	all := make([]fairLine, 0, fb.count)
+	for _, lines := range fb.windows {
+		all = append(all, lines...)
+	}
+	slices.SortFunc(all, func(a, b fairLine) int {
+		return cmp.Compare(a.seq, b.seq)
+	})
+	result := make([]string, len(all))
+	for i, l := range all {
+		result[i] = l.text
+	}
+	return result
+}
+
+// LinesFor returns the completed lines maintained for key.
+func (fb *FairKeyedBuffer) LinesFor(key string) []string {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	result := make([]string, len(fb.windows[key]))
+	for i, l := range fb.windows[key] {
+		result[i] = l.text
+	}
+	return result
+}
+
+// push maintains a completed line under its key, evicting the oldest line of the key with the most lines
+// if the window is full.
+func (fb *FairKeyedBuffer) push(text string) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	if fb.totalLines == 0 {
+		return
+	}
+	key := fb.keyFn(text)
+	fb.seq++
+	fb.windows[key] = append(fb.windows[key], fairLine{seq: fb.seq, text: text})
+	fb.count++
+
+	for fb.count > fb.totalLines {
+		var victim string
+		var most []fairLine
+		for k, lines := range fb.windows {
+			if len(lines) > len(most) || len(lines) == len(most) && lines[0].seq < most[0].seq {
+				victim, most = k, lines
+			}
+		}
+		if len(most) == 1 {
+			delete(fb.windows, victim)
+		} else {
+			most[0] = fairLine{}
+			fb.windows[victim] = most[1:]
+		}
+		fb.count--
+	}
+}
@@ -0,0 +1,82 @@
+package tail
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFairKeyedBuffer(t *testing.T) {
+	source := func(line string) string {
+		key, _, _ := strings.Cut(line, " ")
+		return key
+	}
+
+	tests := []struct {
+		name     string
+		limit    int
+		writes   []string
+		expected []string
+		perKey   map[string][]string
+	}{
+		{
+			name:     "not full",
+			limit:    4,
+			writes:   []string{"a 1\nb 1\na 2\nb"},
+			expected: []string{"a 1", "b 1", "a 2"},
+			perKey: map[string][]string{
+				"a": {"a 1", "a 2"},
+				"b": {"b 1"},
+			},
+		},
+		{
+			name:     "chatty key does not starve quiet keys",
+			limit:    4,
+			writes:   []string{"q 1\na 1\na 2\nr 1\n", "a 3\na 4\na 5\n"},
+			expected: []string{"q 1", "r 1", "a 4", "a 5"},
+			perKey: map[string][]string{
+				"a": {"a 4", "a 5"},
+				"q": {"q 1"},
+				"r": {"r 1"},
+			},
+		},
+		{
+			name:     "tie evicts the oldest line",
+			limit:    4,
+			writes:   []string{"a 1\nb 1\na 2\nb 2\nc 1\n"},
+			expected: []string{"b 1", "a 2", "b 2", "c 1"},
+			perKey: map[string][]string{
+				"a": {"a 2"},
+				"b": {"b 1", "b 2"},
+				"c": {"c 1"},
+			},
+		},
+		{
+			name:     "zero lines",
+			limit:    0,
+			writes:   []string{"a 1\n"},
+			expected: []string{},
+			perKey:   map[string][]string{"a": {}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := NewFairKeyed(tt.limit, source)
+			for _, data := range tt.writes {
+				if _, err := fb.Write([]byte(data)); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			if result := fb.Lines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+			for key, expected := range tt.perKey {
+				if result := fb.LinesFor(key); !slices.Equal(result, expected) {
+					t.Errorf("%s: expected %q, got %q", key, expected, result)
+				}
+			}
+		})
+	}
+}
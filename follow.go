@@ -0,0 +1,93 @@
+package tail
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// follower is an io.Reader that emits lines as they are completed.
+type follower struct {
+	ctx    context.Context
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	queue  []string
+	size   int
+	delim  string
+	notify chan struct{}
+}
+
+// Follower returns an io.Reader that first emits the completed lines currently maintained,
+// then blocks emitting each new line as it is completed, like `tail -f`.
+// Each line is terminated by the primary delimiter. The pending line is emitted once it is completed.
+// Read returns io.EOF once ctx is canceled.
+// If the reader falls behind by more than maxLines lines, the oldest unread lines are dropped,
+// just as the TailBuffer itself drops them. With maxLines of 0, new lines are still emitted,
+// keeping only the newest unread line.
+func (tb *TailBuffer) Follower(ctx context.Context) io.Reader {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	f := &follower{
+		ctx:    ctx,
+		size:   max(tb.maxLines, 1),
+		delim:  tb.primaryDelimiter(),
+		notify: make(chan struct{}, 1),
+	}
+	for _, l := range tb.lines {
+		f.buf.WriteString(l.text)
+		f.buf.WriteString(f.delim)
+	}
+
+	tb.subscribe(f)
+	context.AfterFunc(ctx, func() {
+		tb.unsubscribe(f)
+	})
+
+	return f
+}
+
+// push queues a completed line without blocking, dropping the oldest queued lines exceeding the size.
+func (f *follower) push(text string) {
+	f.mu.Lock()
+	f.queue = append(f.queue, text+f.delim)
+	if len(f.queue) > f.size {
+		f.queue = f.queue[len(f.queue)-f.size:]
+	}
+	f.mu.Unlock()
+
+	select {
+	case f.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Read implements the io.Reader interface.
+func (f *follower) Read(p []byte) (int, error) {
+	for {
+		if f.ctx.Err() != nil {
+			return 0, io.EOF
+		}
+
+		f.mu.Lock()
+		if f.buf.Len() == 0 {
+			for _, s := range f.queue {
+				f.buf.WriteString(s)
+			}
+			f.queue = nil
+		}
+		if f.buf.Len() > 0 {
+			n, _ := f.buf.Read(p)
+			f.mu.Unlock()
+			return n, nil
+		}
+		f.mu.Unlock()
+
+		select {
+		case <-f.ctx.Done():
+			return 0, io.EOF
+		case <-f.notify:
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package tail
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestTailBuffer_Follower(t *testing.T) {
+	tw := New(2)
+	if _, err := tw.Write([]byte("line1\nline2\nline3\npen")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := bufio.NewReader(tw.Follower(ctx))
+
+	readLine := func() string {
+		t.Helper()
+		s, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return s
+	}
+
+	// Current tail
+	for _, expected := range []string{"line2\n", "line3\n"} {
+		if result := readLine(); result != expected {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	}
+
+	// New lines
+	go func() {
+		_, _ = tw.Write([]byte("ding\n"))
+		_, _ = tw.Write([]byte("line5\n"))
+	}()
+	for _, expected := range []string{"pending\n", "line5\n"} {
+		if result := readLine(); result != expected {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	}
+
+	// Cancellation unblocks a pending Read
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := r.ReadString('\n'); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestTailBuffer_FollowerSlowConsumer(t *testing.T) {
+	tw := New(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := tw.Follower(ctx)
+
+	// Writes never block on the follower
+	if _, err := tw.Write([]byte("line1\nline2\nline3\nline4\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	buf := make([]byte, 64)
+	n, err := f.Read(buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "line3\nline4\n"; string(buf[:n]) != expected {
+		t.Errorf("expected %q, got %q", expected, buf[:n])
+	}
+}
@@ -0,0 +1,56 @@
+package tail
+
+import "encoding/binary"
+
+// frameSplitter splits the pending data into records prefixed with their lengths.
+type frameSplitter struct {
+	size  int
+	order binary.ByteOrder
+}
+
+// Records returns the completed records as byte slices.
+// With WithLengthPrefixedFraming, each record is the payload of a frame, without the length prefix.
+func (tb *TailBuffer) Records() [][]byte {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	records := make([][]byte, len(tb.lines))
+	for i, l := range tb.lines {
+		records[i] = []byte(l.text)
+	}
+	return records
+}
+
+// splitFrames splits the complete frames off the pending data, as lines of their payloads.
+// The length prefixes are counted as skipped bytes.
+func (tb *TailBuffer) splitFrames() []line {
+	s := tb.frames
+	var lines []line
+	pending := tb.buffer.Bytes()
+	start := 0
+	for len(pending)-start >= s.size {
+		n := s.length(pending[start : start+s.size])
+		if uint64(len(pending)-start-s.size) < n {
+			break
+		}
+		end := start + s.size + int(n)
+		lines = append(lines, line{text: lineText(tb, pending[start+s.size:end]), skipped: s.size})
+		start = end
+	}
+	tb.buffer.Next(start)
+	return lines
+}
+
+// length decodes a length prefix.
+func (s *frameSplitter) length(b []byte) uint64 {
+	switch s.size {
+	case 1:
+		return uint64(b[0])
+	case 2:
+		return uint64(s.order.Uint16(b))
+	case 4:
+		return uint64(s.order.Uint32(b))
+	default:
+		return s.order.Uint64(b)
+	}
+}
@@ -0,0 +1,84 @@
+package tail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"testing"
+)
+
+func TestTailBuffer_WithLengthPrefixedFraming(t *testing.T) {
+	frame := func(order binary.ByteOrder, size int, payload string) []byte {
+		b := make([]byte, 8)
+		order.PutUint64(b, uint64(len(payload)))
+		if order == binary.BigEndian {
+			b = b[8-size:]
+		} else {
+			b = b[:size]
+		}
+		return append(b, payload...)
+	}
+
+	tests := []struct {
+		name     string
+		size     int
+		order    binary.ByteOrder
+		payloads []string
+		chunk    int
+		expected []string
+	}{
+		{
+			name:     "4-byte big-endian",
+			size:     4,
+			order:    binary.BigEndian,
+			payloads: []string{"rec1", "", "rec\n3\x00", "rec4"},
+			chunk:    1024,
+			expected: []string{"", "rec\n3\x00", "rec4"},
+		},
+		{
+			name:     "partial frames across writes",
+			size:     2,
+			order:    binary.LittleEndian,
+			payloads: []string{"record1", "record2", "record3"},
+			chunk:    3,
+			expected: []string{"record1", "record2", "record3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(3, WithLengthPrefixedFraming(tt.size, tt.order))
+			var data []byte
+			for _, p := range tt.payloads {
+				data = append(data, frame(tt.order, tt.size, p)...)
+			}
+			for chunk := range slices.Chunk(data, tt.chunk) {
+				if _, err := tw.Write(chunk); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			records := tw.Records()
+			if len(records) != len(tt.expected) {
+				t.Fatalf("expected %q, got %q", tt.expected, records)
+			}
+			for i, r := range records {
+				if !bytes.Equal(r, []byte(tt.expected[i])) {
+					t.Errorf("expected %q, got %q", tt.expected[i], r)
+				}
+			}
+		})
+	}
+
+	t.Run("WriteByte", func(t *testing.T) {
+		tw := New(3, WithLengthPrefixedFraming(1, binary.BigEndian))
+		for _, c := range []byte("\x02ab\x01") {
+			if err := tw.WriteByte(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if expected, result := [][]byte{[]byte("ab")}, tw.Records(); !slices.EqualFunc(result, expected, bytes.Equal) {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package tail
+
+import (
+	"bytes"
+	"testing"
+)
+
+func FuzzTailBuffer(f *testing.F) {
+	f.Add(3, []byte("line1\nline2\nline3\nline4\n"), uint8(5))
+	f.Add(0, []byte("line1\nline2"), uint8(1))
+	f.Add(1, []byte("\n\n\r\n"), uint8(2))
+	f.Add(3, []byte("a\r\r\nb\r\n\r"), uint8(1))
+	f.Add(-1, []byte("line1\n"), uint8(3))
+	f.Add(2, []byte{}, uint8(0))
+
+	f.Fuzz(func(t *testing.T, maxLines int, data []byte, chunk uint8) {
+		maxLines %= 16
+		tw := New(maxLines)
+
+		// Write data in chunks of the given size, including empty writes
+		size := int(chunk)
+		for len(data) > 0 {
+			n := min(size, len(data))
+			written, err := tw.Write(data[:n])
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if written != n {
+				t.Fatalf("expected %d bytes written, got %d", n, written)
+			}
+			data = data[n:]
+			if size == 0 {
+				size = 1
+			}
+		}
+
+		lines := tw.Lines()
+		if maxLines > 0 && len(lines) > maxLines {
+			t.Fatalf("expected at most %d lines, got %d", maxLines, len(lines))
+		}
+
+		// RawBytes round-trips through a re-fed buffer
+		raw := tw.RawBytes()
+		refed := New(maxLines)
+		if _, err := refed.Write(raw); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result := refed.RawBytes(); !bytes.Equal(result, raw) {
+			t.Fatalf("expected %q, got %q", raw, result)
+		}
+		// String round-trips through a re-fed buffer
+		s := tw.String()
+		refed = New(maxLines)
+		if _, err := refed.Write([]byte(s)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result := refed.String(); result != s {
+			t.Fatalf("expected %q, got %q", s, result)
+		}
+		if !bytes.Equal(tw.Bytes(), []byte(s)) {
+			t.Fatalf("expected %q, got %q", s, tw.Bytes())
+		}
+	})
+}
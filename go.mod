@@ -1,3 +1,5 @@
 module github.com/k1LoW/tail
 
 go 1.23.10
+
+require golang.org/x/text v0.26.0
@@ -0,0 +1,43 @@
+package tail
+
+import "regexp"
+
+// GrepContext returns the maintained lines matching re, each with up to before preceding and
+// after following lines of context, like `grep -B before -A after`.
+// Overlapping or adjacent blocks are merged into one.
+func (tb *TailBuffer) GrepContext(re *regexp.Regexp, before, after int) [][]string {
+	tb.mu.Lock()
+	snapshot := tb.snapshot()
+	tb.mu.Unlock()
+
+	before = max(before, 0)
+	after = max(after, 0)
+
+	blocks := [][]string{}
+	start, end := -1, -1 // range of the current block
+	flush := func() {
+		if start < 0 {
+			return
+		}
+		block := make([]string, 0, end-start)
+		for _, l := range snapshot[start:end] {
+			block = append(block, l.text)
+		}
+		blocks = append(blocks, block)
+	}
+	for i, l := range snapshot {
+		if !re.MatchString(l.text) {
+			continue
+		}
+		s, e := max(i-before, 0), min(i+after+1, len(snapshot))
+		if start >= 0 && s <= end {
+			end = e
+			continue
+		}
+		flush()
+		start, end = s, e
+	}
+	flush()
+
+	return blocks
+}
@@ -0,0 +1,70 @@
+package tail
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestTailBuffer_GrepContext(t *testing.T) {
+	input := "a\nb\nERROR 1\nc\nd\ne\nf\nERROR 2\ng\nERROR 3\nh\n"
+
+	tests := []struct {
+		name     string
+		before   int
+		after    int
+		expected [][]string
+	}{
+		{
+			name:   "no context",
+			before: 0,
+			after:  0,
+			expected: [][]string{
+				{"ERROR 1"},
+				{"ERROR 2"},
+				{"ERROR 3"},
+			},
+		},
+		{
+			name:   "overlapping blocks are merged",
+			before: 1,
+			after:  1,
+			expected: [][]string{
+				{"b", "ERROR 1", "c"},
+				{"f", "ERROR 2", "g", "ERROR 3", "h"},
+			},
+		},
+		{
+			name:   "context is clamped",
+			before: 5,
+			after:  0,
+			expected: [][]string{
+				{"a", "b", "ERROR 1", "c", "d", "e", "f", "ERROR 2", "g", "ERROR 3"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(20)
+			if _, err := tw.Write([]byte(input)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result := tw.GrepContext(regexp.MustCompile(`^ERROR`), tt.before, tt.after)
+			if !slices.EqualFunc(result, tt.expected, slices.Equal) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+
+	t.Run("no match", func(t *testing.T) {
+		tw := New(20)
+		if _, err := tw.Write([]byte(input)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result := tw.GrepContext(regexp.MustCompile(`FATAL`), 1, 1); result == nil || len(result) != 0 {
+			t.Errorf("expected empty slice, got %q", result)
+		}
+	})
+}
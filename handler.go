@@ -0,0 +1,41 @@
+package tail
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Handler returns an http.Handler serving the lines on GET, e.g. to be mounted at "/debug/tail".
+// With format "text", it serves String as plain text, and with format "json", it serves Snapshot
+// as a JSON object with the fields Lines and Stats. If format is neither of them, the handler responds
+// with 500 Internal Server Error, so that the mistake shows up on the first request.
+func (tb *TailBuffer) Handler(format string) http.Handler {
+	var serve func(w http.ResponseWriter)
+	switch format {
+	case "text":
+		serve = func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = io.WriteString(w, tb.String())
+		}
+	case "json":
+		serve = func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(tb.Snapshot())
+		}
+	default:
+		serve = func(w http.ResponseWriter) {
+			http.Error(w, fmt.Sprintf("tail: unsupported handler format %q", format), http.StatusInternalServerError)
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		serve(w)
+	})
+}
@@ -0,0 +1,73 @@
+package tail
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTailBuffer_Handler(t *testing.T) {
+	tw := New(2)
+	if _, err := tw.Write([]byte("line1\nline2\npen")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		format      string
+		method      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{
+			name:        "text",
+			format:      "text",
+			method:      http.MethodGet,
+			status:      http.StatusOK,
+			contentType: "text/plain; charset=utf-8",
+			body:        "line2\npen",
+		},
+		{
+			name:        "json",
+			format:      "json",
+			method:      http.MethodGet,
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `{"Lines":["line2","pen"],"Stats":{"TotalLines":2,"TotalBytesWritten":15,"Len":2,"RetainedBytes":8,"Overflowed":false}}` + "\n",
+		},
+		{
+			name:        "method not allowed",
+			format:      "text",
+			method:      http.MethodPost,
+			status:      http.StatusMethodNotAllowed,
+			contentType: "text/plain; charset=utf-8",
+			body:        "Method Not Allowed\n",
+		},
+		{
+			name:        "unsupported format",
+			format:      "xml",
+			method:      http.MethodGet,
+			status:      http.StatusInternalServerError,
+			contentType: "text/plain; charset=utf-8",
+			body:        "tail: unsupported handler format \"xml\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tw.Handler(tt.format).ServeHTTP(rec, httptest.NewRequest(tt.method, "/debug/tail", nil))
+
+			if rec.Code != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, rec.Code)
+			}
+			if result := rec.Header().Get("Content-Type"); result != tt.contentType {
+				t.Errorf("expected %q, got %q", tt.contentType, result)
+			}
+			if result := rec.Body.String(); result != tt.body {
+				t.Errorf("expected %q, got %q", tt.body, result)
+			}
+		})
+	}
+}
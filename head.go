@@ -0,0 +1,90 @@
+package tail
+
+import (
+	"io"
+	"strings"
+	"sync"
+)
+
+// HeadBuffer maintains the first N lines of written data, the counterpart of TailBuffer like `head`.
+type HeadBuffer struct {
+	tb       *TailBuffer
+	mu       sync.Mutex
+	maxLines int
+	lines    []string
+}
+
+var _ TailWriter = (*HeadBuffer)(nil)
+
+// NewHead creates a new HeadBuffer maintaining the first maxLines completed lines.
+// opts configure how written data is split into lines, e.g. WithDelimiters.
+// A negative maxLines is treated as 0.
+func NewHead(maxLines int, opts ...Option) *HeadBuffer {
+	hb := &HeadBuffer{
+		tb:       New(1, opts...),
+		maxLines: max(maxLines, 0),
+		lines:    []string{},
+	}
+
+	hb.tb.mu.Lock()
+	hb.tb.subscribe(hb)
+	hb.tb.mu.Unlock()
+	return hb
+}
+
+// Write implements the io.Writer interface.
+// Once the first maxLines lines are completed, data is discarded, and the length of p is returned without an error.
+func (hb *HeadBuffer) Write(p []byte) (n int, err error) {
+	if hb.Full() {
+		return len(p), nil
+	}
+	return hb.tb.Write(p)
+}
+
+// Full reports whether the first maxLines lines have been completed, so that further data is discarded.
+func (hb *HeadBuffer) Full() bool {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+
+	return len(hb.lines) == hb.maxLines
+}
+
+// Lines returns the maintained lines.
+func (hb *HeadBuffer) Lines() []string {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+
+	return append([]string{}, hb.lines...)
+}
+
+// String returns the maintained lines, each followed by the delimiter.
+func (hb *HeadBuffer) String() string {
+	delim := hb.tb.primaryDelimiter()
+
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+
+	var sb strings.Builder
+	for _, l := range hb.lines {
+		sb.WriteString(l)
+		sb.WriteString(delim)
+	}
+	return sb.String()
+}
+
+// WriteTo implements the io.WriterTo interface.
+// It writes the maintained lines to w, as returned by String.
+func (hb *HeadBuffer) WriteTo(w io.Writer) (n int64, err error) {
+	written, err := io.WriteString(w, hb.String())
+	return int64(written), err
+}
+
+// push maintains a completed line until the head is full.
+func (hb *HeadBuffer) push(text string) {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+
+	if len(hb.lines) < hb.maxLines {
+		hb.lines = append(hb.lines, text)
+	}
+}
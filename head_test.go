@@ -0,0 +1,70 @@
+package tail
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHeadBuffer(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		writes   []string
+		expected []string
+		full     bool
+	}{
+		{
+			name:     "not full",
+			limit:    3,
+			writes:   []string{"line1\nli", "ne2\nline3"},
+			expected: []string{"line1", "line2"},
+			full:     false,
+		},
+		{
+			name:     "subsequent data is discarded",
+			limit:    2,
+			writes:   []string{"line1\nline2\nline3\n", "line4\n"},
+			expected: []string{"line1", "line2"},
+			full:     true,
+		},
+		{
+			name:     "zero lines",
+			limit:    0,
+			writes:   []string{"line1\n"},
+			expected: []string{},
+			full:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hb := NewHead(tt.limit)
+			for _, data := range tt.writes {
+				n, err := hb.Write([]byte(data))
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if n != len(data) {
+					t.Errorf("expected %d, got %d", len(data), n)
+				}
+			}
+
+			if result := hb.Lines(); !slices.Equal(result, tt.expected) || result == nil {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+			if result := hb.Full(); result != tt.full {
+				t.Errorf("expected %v, got %v", tt.full, result)
+			}
+		})
+	}
+
+	t.Run("String", func(t *testing.T) {
+		hb := NewHead(2, WithDelimiters(';'))
+		if _, err := hb.Write([]byte("a;b;c;")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected, result := "a;b;", hb.String(); result != expected {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package tail
+
+import (
+	"context"
+	"regexp"
+	"time"
+)
+
+// WaitIdle waits until no write has occurred for quiet, e.g. to take a snapshot after a burst of writes settles.
+// The quiet period starts when it is called and restarts whenever a write is observed at the end of the period,
+// so it returns between quiet and twice quiet after the last write. It returns ctx.Err() if ctx is done first.
+func (tb *TailBuffer) WaitIdle(ctx context.Context, quiet time.Duration) error {
+	tb.mu.Lock()
+	writes := tb.writes
+	tb.mu.Unlock()
+
+	timer := time.NewTimer(quiet)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		tb.mu.Lock()
+		current := tb.writes
+		tb.mu.Unlock()
+		if current == writes {
+			return nil
+		}
+		writes = current
+		timer.Reset(quiet)
+	}
+}
+
+// matcher is a subscriber that receives the first completed line matching re.
+type matcher struct {
+	re    *regexp.Regexp
+	match chan string
+}
+
+// push sends text if it is the first line matching without blocking.
+func (m *matcher) push(text string) {
+	if !m.re.MatchString(text) {
+		return
+	}
+	select {
+	case m.match <- text:
+	default:
+	}
+}
+
+// WaitFor returns the first completed line matching re, among the maintained lines and then the lines completed
+// after the call, e.g. to wait until a server captured reports that it is ready.
+// It blocks until such a line is completed, or returns ctx.Err() if ctx is done first.
+// The pending line is not matched until it is completed.
+func (tb *TailBuffer) WaitFor(ctx context.Context, re *regexp.Regexp) (string, error) {
+	m := &matcher{re: re, match: make(chan string, 1)}
+
+	tb.mu.Lock()
+	for _, l := range tb.lines {
+		if re.MatchString(l.text) {
+			tb.mu.Unlock()
+			return l.text, nil
+		}
+	}
+	tb.subscribe(m)
+	tb.mu.Unlock()
+	defer tb.unsubscribe(m)
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case text := <-m.match:
+		return text, nil
+	}
+}
@@ -0,0 +1,119 @@
+package tail
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestTailBuffer_WaitIdle(t *testing.T) {
+	t.Run("no writes", func(t *testing.T) {
+		tw := New(3)
+		start := time.Now()
+		if err := tw.WaitIdle(context.Background(), 10*time.Millisecond); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+			t.Errorf("expected to wait for %v, waited %v", 10*time.Millisecond, elapsed)
+		}
+	})
+
+	t.Run("waits for writes to pause", func(t *testing.T) {
+		tw := New(3)
+		quiet := 50 * time.Millisecond
+		writerDone := make(chan time.Time)
+		go func() {
+			for range 5 {
+				_, _ = tw.Write([]byte("line\n"))
+				time.Sleep(quiet / 5)
+			}
+			writerDone <- time.Now()
+		}()
+		time.Sleep(quiet / 10)
+
+		if err := tw.WaitIdle(context.Background(), quiet); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		idle := time.Now()
+		if last := <-writerDone; idle.Sub(last) < quiet-quiet/5 {
+			t.Errorf("expected to wait for %v after the last write, waited %v", quiet, idle.Sub(last))
+		}
+		if result := tw.TotalLines(); result != 5 {
+			t.Errorf("expected 5 lines, got %d", result)
+		}
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		tw := New(3)
+		if _, err := tw.Write([]byte("line\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := tw.WaitIdle(ctx, time.Hour); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+		}
+	})
+}
+
+func TestTailBuffer_WaitFor(t *testing.T) {
+	ready := regexp.MustCompile(`^Server started on :\d+$`)
+
+	t.Run("maintained line", func(t *testing.T) {
+		tw := New(3)
+		if _, err := tw.Write([]byte("booting\nServer started on :8080\nserving")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		line, err := tw.WaitFor(context.Background(), ready)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := "Server started on :8080"; line != expected {
+			t.Errorf("expected %q, got %q", expected, line)
+		}
+	})
+
+	t.Run("line completed later", func(t *testing.T) {
+		tw := New(3)
+		if _, err := tw.Write([]byte("booting\nServer started")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			_, _ = tw.Write([]byte(" on :8080\nServer started on :8081\n"))
+		}()
+		line, err := tw.WaitFor(context.Background(), ready)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := "Server started on :8080"; line != expected {
+			t.Errorf("expected %q, got %q", expected, line)
+		}
+	})
+
+	t.Run("max lines of 0", func(t *testing.T) {
+		tw := New(0)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			_, _ = tw.Write([]byte("Server started on :8080\n"))
+		}()
+		line, err := tw.WaitFor(context.Background(), ready)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := "Server started on :8080"; line != expected {
+			t.Errorf("expected %q, got %q", expected, line)
+		}
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		tw := New(3)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if _, err := tw.WaitFor(ctx, ready); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+		}
+	})
+}
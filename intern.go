@@ -0,0 +1,34 @@
+package tail
+
+// internCapacity is the maximum number of strings kept by the interner.
+const internCapacity = 4096
+
+// interner shares one allocation between identical line texts.
+// It is cleared once it holds internCapacity strings, so its size is bounded
+// while the texts repeating often are interned again soon.
+type interner struct {
+	m map[string]string
+}
+
+// internString returns the interned copy of s, interning it if it is not interned yet.
+func internString[S ~string | ~[]byte](tb *TailBuffer, s S) string {
+	if len(s) == 0 {
+		return ""
+	}
+	in := tb.interner
+	if t, ok := in.m[string(s)]; ok {
+		return t
+	}
+
+	var t string
+	if tb.arena != nil {
+		t = arenaString(tb.arena, s)
+	} else {
+		t = string(s)
+	}
+	if len(in.m) >= internCapacity {
+		clear(in.m)
+	}
+	in.m[t] = t
+	return t
+}
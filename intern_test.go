@@ -0,0 +1,62 @@
+package tail
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+	"unsafe"
+)
+
+func TestTailBuffer_WithStringInterning(t *testing.T) {
+	tw := New(4, WithStringInterning())
+	writes := []string{"repeated\nunique1\nrep", "eated\n", "repeated\n"}
+	for _, data := range writes {
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	lines := tw.Lines()
+	expected := []string{"repeated", "unique1", "repeated", "repeated"}
+	if !slices.Equal(lines, expected) {
+		t.Errorf("expected %q, got %q", expected, lines)
+	}
+
+	// Identical lines share the backing array
+	tw.mu.Lock()
+	first, last := tw.lines[0].text, tw.lines[3].text
+	tw.mu.Unlock()
+	if unsafe.StringData(first) != unsafe.StringData(last) {
+		t.Error("expected identical lines to be interned")
+	}
+
+	// The interned strings are bounded
+	for i := range internCapacity + 1 {
+		if _, err := tw.Write([]byte(strconv.Itoa(i) + "\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if result := len(tw.interner.m); result > internCapacity {
+		t.Errorf("expected at most %d strings, got %d", internCapacity, result)
+	}
+}
+
+func BenchmarkTailBuffer_WriteRepeated(b *testing.B) {
+	for _, bm := range []struct {
+		name string
+		opts []Option
+	}{
+		{"default", nil},
+		{"interning", []Option{WithStringInterning()}},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			tw := New(1000, bm.opts...)
+			data := [][]byte{[]byte("This is a repeated line\n"), []byte("This is another repeated line\n")}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = tw.Write(data[i%2])
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package tail
+
+import "encoding/json"
+
+// jsonSplitter tracks the nesting of the pending data to split it into top-level JSON values.
+type jsonSplitter struct {
+	depth    int
+	inString bool
+	escape   bool
+	// scanned is the number of bytes of the pending data already scanned.
+	scanned int
+	// skipped is the number of bytes discarded before the pending value.
+	skipped int
+}
+
+// JSONValues returns the completed lines as raw JSON values.
+// With WithJSONObjectSplit, each line is a top-level JSON object or array.
+func (tb *TailBuffer) JSONValues() []json.RawMessage {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	values := make([]json.RawMessage, len(tb.lines))
+	for i, l := range tb.lines {
+		values[i] = json.RawMessage(l.text)
+	}
+	return values
+}
+
+// splitJSON splits the complete top-level JSON values off the pending data,
+// discarding whitespace and any other bytes outside of them.
+// Only the data written since the last call is scanned.
+func (tb *TailBuffer) splitJSON() []line {
+	s := tb.json
+	var lines []line
+	pending := tb.buffer.Bytes()
+	start := 0
+	for i := s.scanned; i < len(pending); i++ {
+		c := pending[i]
+		switch {
+		case s.depth == 0 && c != '{' && c != '[':
+			// Whitespace between values, or stray bytes that do not begin a value
+			start++
+			s.skipped++
+		case s.inString:
+			switch {
+			case s.escape:
+				s.escape = false
+			case c == '\\':
+				s.escape = true
+			case c == '"':
+				s.inString = false
+			}
+		case c == '"' && s.depth > 0:
+			s.inString = true
+		case c == '{' || c == '[':
+			s.depth++
+		case (c == '}' || c == ']') && s.depth > 0:
+			s.depth--
+			if s.depth == 0 {
+				lines = append(lines, line{text: lineText(tb, pending[start:i+1]), skipped: s.skipped})
+				s.skipped = 0
+				start = i + 1
+			}
+		}
+	}
+	tb.buffer.Next(start)
+	s.scanned = tb.buffer.Len()
+	return lines
+}
@@ -0,0 +1,107 @@
+package tail
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTailBuffer_WithJSONObjectSplit(t *testing.T) {
+	tests := []struct {
+		name            string
+		limit           int
+		writes          []string
+		expected        []string
+		expectedPending string
+	}{
+		{
+			name:     "one object per line",
+			limit:    2,
+			writes:   []string{"{\"a\":1}\n{\"a\":2}\n{\"a\":3}\n"},
+			expected: []string{`{"a":2}`, `{"a":3}`},
+		},
+		{
+			name:     "pretty-printed objects",
+			limit:    3,
+			writes:   []string{"{\n  \"a\": {\n    \"b\": [1, 2]\n  }\n}\n{\n  \"c\": 3\n}\n"},
+			expected: []string{"{\n  \"a\": {\n    \"b\": [1, 2]\n  }\n}", "{\n  \"c\": 3\n}"},
+		},
+		{
+			name:     "back-to-back objects",
+			limit:    3,
+			writes:   []string{`{"a":1}{"a":2}[3]`},
+			expected: []string{`{"a":1}`, `{"a":2}`, `[3]`},
+		},
+		{
+			name:     "braces and escaped quotes in strings",
+			limit:    3,
+			writes:   []string{`{"a":"}{\"","b":"\\"}` + "\n"},
+			expected: []string{`{"a":"}{\"","b":"\\"}`},
+		},
+		{
+			name:     "stray bytes between values",
+			limit:    3,
+			writes:   []string{"x{\"a\":1}", " 42 }]\n", "null[2]"},
+			expected: []string{`{"a":1}`, `[2]`},
+		},
+		{
+			name:            "object spanning many writes",
+			limit:           3,
+			writes:          []string{`{"a":`, `"x\`, `""}`, "\n", `{"b":[`, "1,"},
+			expected:        []string{`{"a":"x\""}`, `{"b":[1,`},
+			expectedPending: `{"b":[1,`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, byByte := range []bool{false, true} {
+				tw := New(tt.limit, WithJSONObjectSplit())
+				for _, data := range tt.writes {
+					if byByte {
+						for i := range len(data) {
+							if err := tw.WriteByte(data[i]); err != nil {
+								t.Fatalf("unexpected error: %v", err)
+							}
+						}
+						continue
+					}
+					if _, err := tw.Write([]byte(data)); err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+				}
+
+				if result := tw.Lines(); !slices.Equal(result, tt.expected) {
+					t.Errorf("byByte=%v: expected %q, got %q", byByte, tt.expected, result)
+				}
+				if tt.expectedPending == "" {
+					if !tw.AtLineBoundary() {
+						t.Errorf("byByte=%v: expected no pending value", byByte)
+					}
+					for _, v := range tw.JSONValues() {
+						if !json.Valid(v) {
+							t.Errorf("byByte=%v: expected valid JSON, got %q", byByte, v)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTailBuffer_WithJSONObjectSplit_Offsets(t *testing.T) {
+	input := "{\"a\":1}\n\n  {\"b\":2}\n"
+	tw := New(2, WithJSONObjectSplit())
+	if _, err := tw.Write([]byte(input)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, l := range tw.OffsetLines() {
+		if !strings.HasPrefix(input[l.Offset:], l.Line) {
+			t.Errorf("expected %q at offset %d, got %q", l.Line, l.Offset, input[l.Offset:])
+		}
+	}
+	if expected, result := "{\"a\":1}\n{\"b\":2}\n", tw.String(); result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
@@ -0,0 +1,105 @@
+package tail
+
+import (
+	"container/list"
+	"slices"
+	"sync"
+)
+
+// KeyedBuffer maintains the last N lines for each key, such as a tenant, that lines are routed by.
+type KeyedBuffer struct {
+	tb             *TailBuffer
+	mu             sync.Mutex
+	maxLinesPerKey int
+	keyFn          func(line string) string
+	// windows maps each key to its element in recent, holding a *keyedWindow.
+	windows map[string]*list.Element
+	// recent orders the keys by their latest line, the most recent first.
+	recent *list.List
+}
+
+// keyedWindow is the lines maintained for a key.
+type keyedWindow struct {
+	key   string
+	lines []string
+}
+
+// NewKeyed creates a new KeyedBuffer maintaining the last maxLinesPerKey lines for each key returned by keyFn
+// for the completed lines. opts configure how written data is split into lines, e.g. WithDelimiters,
+// and WithMaxKeys bounds the number of keys.
+// A negative maxLinesPerKey is treated as 0.
+func NewKeyed(maxLinesPerKey int, keyFn func(line string) string, opts ...Option) *KeyedBuffer {
+	kb := &KeyedBuffer{
+		tb:             New(1, opts...),
+		maxLinesPerKey: max(maxLinesPerKey, 0),
+		keyFn:          keyFn,
+		windows:        map[string]*list.Element{},
+		recent:         list.New(),
+	}
+
+	kb.tb.mu.Lock()
+	kb.tb.subscribe(kb)
+	kb.tb.mu.Unlock()
+	return kb
+}
+
+// Write implements the io.Writer interface.
+// It writes data and routes each completed line to the lines of its key.
+func (kb *KeyedBuffer) Write(p []byte) (n int, err error) {
+	return kb.tb.Write(p)
+}
+
+// LinesFor returns the completed lines maintained for key.
+func (kb *KeyedBuffer) LinesFor(key string) []string {
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+
+	e, ok := kb.windows[key]
+	if !ok {
+		return []string{}
+	}
+	return append([]string{}, e.Value.(*keyedWindow).lines...)
+}
+
+// Keys returns the keys that lines are maintained for, in sorted order.
+func (kb *KeyedBuffer) Keys() []string {
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+
+	keys := make([]string, 0, len(kb.windows))
+	for key := range kb.windows {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// push routes a completed line to the lines of its key, dropping the least recently written key
+// if the number of keys exceeds the limit set by WithMaxKeys.
+func (kb *KeyedBuffer) push(text string) {
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+
+	key := kb.keyFn(text)
+	e, ok := kb.windows[key]
+	if ok {
+		kb.recent.MoveToFront(e)
+	} else {
+		e = kb.recent.PushFront(&keyedWindow{key: key})
+		kb.windows[key] = e
+		if kb.tb.maxKeys > 0 && kb.recent.Len() > kb.tb.maxKeys {
+			oldest := kb.recent.Remove(kb.recent.Back()).(*keyedWindow)
+			delete(kb.windows, oldest.key)
+		}
+	}
+
+	w := e.Value.(*keyedWindow)
+	if kb.maxLinesPerKey == 0 {
+		return
+	}
+	if len(w.lines) == kb.maxLinesPerKey {
+		copy(w.lines, w.lines[1:])
+		w.lines = w.lines[:len(w.lines)-1]
+	}
+	w.lines = append(w.lines, text)
+}
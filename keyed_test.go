@@ -0,0 +1,90 @@
+package tail
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestKeyedBuffer(t *testing.T) {
+	tenant := func(line string) string {
+		key, _, _ := strings.Cut(line, " ")
+		return key
+	}
+
+	tests := []struct {
+		name     string
+		limit    int
+		opts     []Option
+		writes   []string
+		expected map[string][]string
+	}{
+		{
+			name:   "last lines per key",
+			limit:  2,
+			writes: []string{"a 1\nb 1\na 2\n", "a 3\nb 2\n"},
+			expected: map[string][]string{
+				"a": {"a 2", "a 3"},
+				"b": {"b 1", "b 2"},
+			},
+		},
+		{
+			name:   "pending line is not routed",
+			limit:  2,
+			writes: []string{"a 1\nb 1"},
+			expected: map[string][]string{
+				"a": {"a 1"},
+			},
+		},
+		{
+			name:   "least recently written key is dropped",
+			limit:  2,
+			opts:   []Option{WithMaxKeys(2)},
+			writes: []string{"a 1\nb 1\na 2\nc 1\n"},
+			expected: map[string][]string{
+				"a": {"a 1", "a 2"},
+				"c": {"c 1"},
+			},
+		},
+		{
+			name:   "delimiter option",
+			limit:  1,
+			opts:   []Option{WithDelimiters(';')},
+			writes: []string{"a 1;a 2;b 1;"},
+			expected: map[string][]string{
+				"a": {"a 2"},
+				"b": {"b 1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kb := NewKeyed(tt.limit, tenant, tt.opts...)
+			for _, data := range tt.writes {
+				if _, err := kb.Write([]byte(data)); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			keys := []string{}
+			for key, expected := range tt.expected {
+				keys = append(keys, key)
+				if result := kb.LinesFor(key); !slices.Equal(result, expected) {
+					t.Errorf("key %q: expected %q, got %q", key, expected, result)
+				}
+			}
+			slices.Sort(keys)
+			if result := kb.Keys(); !slices.Equal(result, keys) {
+				t.Errorf("expected %q, got %q", keys, result)
+			}
+		})
+	}
+}
+
+func TestKeyedBuffer_UnknownKey(t *testing.T) {
+	kb := NewKeyed(2, func(line string) string { return line })
+	if result := kb.LinesFor("unknown"); result == nil || len(result) != 0 {
+		t.Errorf("expected empty slice, got %q", result)
+	}
+}
@@ -0,0 +1,60 @@
+package tail
+
+import (
+	"slices"
+	"time"
+)
+
+// latencySamples is the number of the latest write latencies kept by WithLatencyTracking.
+const latencySamples = 1024
+
+// latencyReservoir keeps the latest write latencies in a ring.
+type latencyReservoir struct {
+	samples []time.Duration
+	next    int
+}
+
+// startLatency returns the start time of a write, or the zero time if WithLatencyTracking is not set.
+func (tb *TailBuffer) startLatency() time.Time {
+	if tb.latency == nil {
+		return time.Time{}
+	}
+	return tb.clock()
+}
+
+// recordLatency records the latency of a write started at start.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) recordLatency(start time.Time) {
+	r := tb.latency
+	if r == nil {
+		return
+	}
+	d := tb.clock().Sub(start)
+	if len(r.samples) < latencySamples {
+		r.samples = append(r.samples, d)
+		return
+	}
+	r.samples[r.next] = d
+	r.next = (r.next + 1) % latencySamples
+}
+
+// WriteLatency returns the median and the 99th percentile of the latencies of the latest 1024 writes,
+// including the time waiting for the lock. It returns zeros if WithLatencyTracking is not set or nothing is written.
+func (tb *TailBuffer) WriteLatency() (p50, p99 time.Duration) {
+	tb.mu.Lock()
+	if tb.latency == nil || len(tb.latency.samples) == 0 {
+		tb.mu.Unlock()
+		return 0, 0
+	}
+	samples := slices.Clone(tb.latency.samples)
+	tb.mu.Unlock()
+
+	slices.Sort(samples)
+	return percentile(samples, 50), percentile(samples, 99)
+}
+
+// percentile returns the pth percentile of the sorted samples by the nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank-1, 0)]
+}
@@ -0,0 +1,50 @@
+package tail
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTailBuffer_WriteLatency(t *testing.T) {
+	// Each write reads the clock at its start and its end
+	var now time.Time
+	var durations []time.Duration
+	started := false
+	clock := func() time.Time {
+		if started {
+			now = now.Add(durations[0])
+			durations = durations[1:]
+		}
+		started = !started
+		return now
+	}
+
+	tw := New(3, WithLatencyTracking(), WithClock(clock))
+	if p50, p99 := tw.WriteLatency(); p50 != 0 || p99 != 0 {
+		t.Errorf("expected zeros, got %v and %v", p50, p99)
+	}
+
+	for i := range 100 {
+		durations = append(durations, time.Duration(i+1)*time.Microsecond)
+	}
+	for range 99 {
+		if _, err := tw.Write([]byte("line\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := tw.WriteByte('\n'); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	p50, p99 := tw.WriteLatency()
+	if expected := 50 * time.Microsecond; p50 != expected {
+		t.Errorf("expected %v, got %v", expected, p50)
+	}
+	if expected := 99 * time.Microsecond; p99 != expected {
+		t.Errorf("expected %v, got %v", expected, p99)
+	}
+
+	if p50, p99 := New(3).WriteLatency(); p50 != 0 || p99 != 0 {
+		t.Errorf("expected zeros, got %v and %v", p50, p99)
+	}
+}
@@ -0,0 +1,105 @@
+package tail
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// LogfmtRawKey is the key under which a line that cannot be parsed as logfmt is kept.
+const LogfmtRawKey = "_raw"
+
+// LogfmtBuffer is a TailBuffer that parses maintained lines as logfmt records.
+type LogfmtBuffer struct {
+	*TailBuffer
+}
+
+// NewLogfmt creates a new LogfmtBuffer with the specified maximum number of lines.
+func NewLogfmt(maxLines int, opts ...Option) *LogfmtBuffer {
+	return &LogfmtBuffer{
+		TailBuffer: New(maxLines, opts...),
+	}
+}
+
+// Records returns the completed lines parsed as logfmt records (`key=value key2="value 2"`).
+// A line that cannot be parsed is returned as a record holding the raw line under LogfmtRawKey.
+// The raw lines are still available via Lines.
+func (lb *LogfmtBuffer) Records() []map[string]string {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	records := make([]map[string]string, len(lb.lines))
+	for i, l := range lb.lines {
+		record, err := parseLogfmt(l.text)
+		if err != nil {
+			record = map[string]string{LogfmtRawKey: l.text}
+		}
+		records[i] = record
+	}
+	return records
+}
+
+// parseLogfmt parses a single logfmt line.
+func parseLogfmt(s string) (map[string]string, error) {
+	record := map[string]string{}
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return record, nil
+		}
+
+		// Key
+		end := strings.IndexAny(s, "= \t")
+		if end < 0 {
+			end = len(s)
+		}
+		key := s[:end]
+		if key == "" || strings.ContainsRune(key, '"') {
+			return nil, errors.New("invalid logfmt key")
+		}
+		s = s[end:]
+		if !strings.HasPrefix(s, "=") {
+			// A bare key has an empty value
+			record[key] = ""
+			continue
+		}
+		s = s[1:]
+
+		// Value
+		if strings.HasPrefix(s, `"`) {
+			end = closingQuote(s)
+			if end < 0 {
+				return nil, errors.New("unterminated quoted logfmt value")
+			}
+			v, err := strconv.Unquote(s[:end+1])
+			if err != nil {
+				return nil, err
+			}
+			record[key] = v
+			s = s[end+1:]
+			continue
+		}
+		end = strings.IndexAny(s, " \t")
+		if end < 0 {
+			end = len(s)
+		}
+		if strings.ContainsRune(s[:end], '"') {
+			return nil, errors.New("invalid logfmt value")
+		}
+		record[key] = s[:end]
+		s = s[end:]
+	}
+}
+
+// closingQuote returns the index of the quote closing the quoted string at the beginning of s, or -1.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,69 @@
+package tail
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestLogfmtBuffer_Records(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		input    string
+		expected []map[string]string
+	}{
+		{
+			name:  "basic",
+			limit: 2,
+			input: "level=info msg=start\nlevel=warn msg=\"disk almost full\" used=91\nlevel=info msg=done\n",
+			expected: []map[string]string{
+				{"level": "warn", "msg": "disk almost full", "used": "91"},
+				{"level": "info", "msg": "done"},
+			},
+		},
+		{
+			name:  "bare key and escaped quote",
+			limit: 2,
+			input: "debug msg=\"say \\\"hi\\\"\" empty=\n",
+			expected: []map[string]string{
+				{"debug": "", "msg": `say "hi"`, "empty": ""},
+			},
+		},
+		{
+			name:  "parse error",
+			limit: 2,
+			input: "msg=\"unterminated\n=value\n",
+			expected: []map[string]string{
+				{LogfmtRawKey: "msg=\"unterminated"},
+				{LogfmtRawKey: "=value"},
+			},
+		},
+		{
+			name:  "pending line is not a record",
+			limit: 2,
+			input: "a=1\nb=2",
+			expected: []map[string]string{
+				{"a": "1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lb := NewLogfmt(tt.limit)
+			if _, err := lb.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result := lb.Records()
+			if len(result) != len(tt.expected) {
+				t.Fatalf("expected %d records, got %d: %v", len(tt.expected), len(result), result)
+			}
+			for i, record := range result {
+				if !maps.Equal(record, tt.expected[i]) {
+					t.Errorf("record %d: expected %v, got %v", i, tt.expected[i], record)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package tail
+
+import (
+	"strings"
+	"unsafe"
+)
+
+// lineOverhead is the size of the bookkeeping of a maintained line accounted by WithMemoryCap.
+const lineOverhead = int(unsafe.Sizeof(storedLine{}))
+
+// footprint returns the number of bytes accounted by WithMemoryCap for a line of text terminated by term,
+// including its metadata.
+func (tb *TailBuffer) footprint(text, term string) int {
+	return lineOverhead + tb.meta.size() + len(text) + len(term)
+}
+
+// enforceMemoryCap truncates the pending line and the lines completed by the last write, and evicts the oldest lines,
+// so that the footprint of the buffer does not exceed the cap set by WithMemoryCap.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) enforceMemoryCap() {
+	if tb.memoryCap <= 0 {
+		return
+	}
+	if tb.buffer.Len() > tb.memoryCap {
+		tb.buffer.Truncate(tb.memoryCap)
+		if tb.json != nil {
+			tb.json.scanned = min(tb.json.scanned, tb.buffer.Len())
+		}
+	}
+
+	used := tb.buffer.Len() + len(tb.lines)*tb.footprint("", "") + tb.lineBytes
+	if used <= tb.memoryCap {
+		return
+	}
+	start := 0
+	for used > tb.memoryCap && start < len(tb.lines) {
+		used -= tb.footprint(tb.lines[start].text, tb.lines[start].term)
+		start++
+	}
+	if start > 0 {
+		tb.recordEvictions(0, start, EvictedByMemoryCap)
+		tb.forgetLines(tb.lines[:start])
+		clear(tb.lines[:start])
+		tb.lines = tb.lines[start:]
+		tb.meta.drop(start)
+		tb.overflowed = true
+		tb.evictedLines += start
+	}
+}
+
+// truncateToMemoryCap truncates the text of l so that its footprint does not exceed the cap set by WithMemoryCap.
+// The text is copied so that the rest of it can be freed.
+func (tb *TailBuffer) truncateToMemoryCap(l *line) {
+	if tb.memoryCap <= 0 || tb.footprint(l.text, l.term) <= tb.memoryCap {
+		return
+	}
+	l.text = strings.Clone(l.text[:max(tb.memoryCap-tb.footprint("", l.term), 0)])
+}
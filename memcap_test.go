@@ -0,0 +1,181 @@
+package tail
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTailBuffer_WithMemoryCap(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     int
+		memoryCap int
+		writes    []string
+		expected  []string
+	}{
+		{
+			name:      "evicts the oldest lines",
+			limit:     10,
+			memoryCap: 3 * (lineOverhead + 6),
+			writes:    []string{"line1\nline2\nline3\nline4\n"},
+			expected:  []string{"line2", "line3", "line4"},
+		},
+		{
+			name:      "truncates a line that does not fit",
+			limit:     10,
+			memoryCap: lineOverhead + 5,
+			writes:    []string{"short\n", strings.Repeat("a", 100) + "\n"},
+			expected:  []string{"aaaa"},
+		},
+		{
+			name:      "truncates a long pending line",
+			limit:     10,
+			memoryCap: lineOverhead + 10,
+			writes:    []string{"line1\n", strings.Repeat("b", 50), strings.Repeat("c", 200)},
+			expected:  []string{strings.Repeat("b", 50) + strings.Repeat("c", lineOverhead-40)},
+		},
+		{
+			name:      "pending line makes room",
+			limit:     10,
+			memoryCap: 2*(lineOverhead+6) + 2,
+			writes:    []string{"line1\nline2\npen"},
+			expected:  []string{"line2", "pen"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, byByte := range []bool{false, true} {
+				tw := New(tt.limit, WithMemoryCap(tt.memoryCap))
+				for _, data := range tt.writes {
+					if byByte {
+						for i := range len(data) {
+							if err := tw.WriteByte(data[i]); err != nil {
+								t.Fatalf("unexpected error: %v", err)
+							}
+						}
+						continue
+					}
+					if _, err := tw.Write([]byte(data)); err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+				}
+
+				if result := tw.Lines(); !slices.Equal(result, tt.expected) {
+					t.Errorf("byByte=%v: expected %q, got %q", byByte, tt.expected, result)
+				}
+
+				tw.mu.Lock()
+				used := tw.buffer.Len()
+				for _, l := range tw.lines {
+					used += tw.footprint(l.text, l.term)
+				}
+				tw.mu.Unlock()
+				if used > tt.memoryCap {
+					t.Errorf("byByte=%v: expected at most %d bytes, got %d", byByte, tt.memoryCap, used)
+				}
+			}
+		})
+	}
+}
+
+func TestTailBuffer_LineBytes(t *testing.T) {
+	indented := func(line string) bool { return strings.HasPrefix(line, " ") }
+	tests := []struct {
+		name string
+		opts []Option
+		ops  func(tw *TailBuffer)
+	}{
+		{"evicted", nil, func(tw *TailBuffer) {}},
+		{"continuation", []Option{WithContinuation(indented)}, func(tw *TailBuffer) {}},
+		{"max runes", []Option{WithMaxRunes(8)}, func(tw *TailBuffer) {}},
+		{"max runes and continuation", []Option{WithMaxRunes(12), WithContinuation(indented)}, func(tw *TailBuffer) {}},
+		{"max runes and replace", []Option{WithMaxRunes(8)}, func(tw *TailBuffer) { tw.ReplaceInLines("ine", "") }},
+		{"drop newest", []Option{WithShrinkPolicy(DropNewest)}, func(tw *TailBuffer) {}},
+		{"replace", nil, func(tw *TailBuffer) { tw.ReplaceInLines("line", "l") }},
+		{"shrink", nil, func(tw *TailBuffer) { tw.SetMaxLines(1) }},
+		{"shrink to zero", nil, func(tw *TailBuffer) { tw.SetMaxLines(0) }},
+		{"reset", nil, func(tw *TailBuffer) { tw.Reset() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(3, tt.opts...)
+			if _, err := tw.Write([]byte("line1\r\n  more\nline2\nline3\n  more\nline4\n")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.ops(tw)
+
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			expected, expectedTerms, expectedRunes := 0, 0, 0
+			for _, l := range tw.lines {
+				expected += len(l.text) + len(l.term)
+				expectedTerms += len(l.term)
+				expectedRunes += utf8.RuneCountInString(l.text)
+			}
+			if tw.lineBytes != expected {
+				t.Errorf("expected %d, got %d", expected, tw.lineBytes)
+			}
+			if tw.termBytes != expectedTerms {
+				t.Errorf("expected %d, got %d", expectedTerms, tw.termBytes)
+			}
+			if tw.maxRunes > 0 && tw.runes != expectedRunes {
+				t.Errorf("expected %d, got %d", expectedRunes, tw.runes)
+			}
+		})
+	}
+}
+
+func BenchmarkTailBuffer_WriteByteMemoryCap(b *testing.B) {
+	tw := New(100_000, WithMemoryCap(1<<30))
+	for i := range 100_000 {
+		_, _ = tw.Write([]byte(strconv.Itoa(i) + "\n"))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c := byte('a')
+		if i%32 == 31 {
+			c = '\n'
+		}
+		_ = tw.WriteByte(c)
+	}
+}
+
+func TestTailBuffer_EvictedLinesReleased(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"max lines", nil},
+		{"drop newest", []Option{WithShrinkPolicy(DropNewest)}},
+		{"max runes", []Option{WithMaxRunes(10)}},
+		{"memory cap", []Option{WithMemoryCap(300)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(3, tt.opts...)
+			for i := range 10 {
+				if _, err := tw.Write([]byte("line" + strconv.Itoa(i) + "\n")); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			// Only the maintained lines are referenced by the backing array
+			referenced := 0
+			for _, l := range tw.store[:cap(tw.store)] {
+				if l.text != "" {
+					referenced++
+				}
+			}
+			if referenced != len(tw.lines) {
+				t.Errorf("expected %d lines referenced, got %d", len(tw.lines), referenced)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package tail
+
+import (
+	"slices"
+	"time"
+)
+
+// Merge creates a new TailBuffer maintaining the last maxLines of the lines of bufs.
+// If all of bufs record timestamps with WithTimestamps, the lines are interleaved in the order
+// they were completed, and the returned TailBuffer records timestamps too; the pending line of each
+// buffer is treated as the newest line of that buffer. Otherwise, the lines are concatenated
+// in argument order, so recency across buffers is not preserved.
+// Each buffer is snapshotted under its own lock.
+func Merge(maxLines int, bufs ...*TailBuffer) *TailBuffer {
+	timestamps := len(bufs) > 0
+	var merged []line
+	for _, b := range bufs {
+		b.mu.Lock()
+		snapshot := b.snapshot()
+		pending := b.buffer.Len() > 0 && !b.pendingHidden()
+		clock := b.clock
+		timestamps = timestamps && b.timestamps
+		b.mu.Unlock()
+
+		if n := len(snapshot); n > 0 && pending {
+			snapshot[n-1].time = clock()
+		}
+		merged = append(merged, snapshot...)
+	}
+
+	var opts []Option
+	if timestamps {
+		slices.SortStableFunc(merged, func(a, b line) int {
+			return a.time.Compare(b.time)
+		})
+		opts = append(opts, WithTimestamps())
+	}
+
+	tb := New(maxLines, opts...)
+	if maxLines <= 0 {
+		return tb
+	}
+	for _, l := range merged[max(len(merged)-maxLines, 0):] {
+		if l.term == "" {
+			// The pending line of a buffer is complete in the merged one
+			l.term = tb.primaryDelimiter()
+		}
+		if !timestamps {
+			l.time = time.Time{}
+		}
+		tb.pushLine(&l)
+	}
+	return tb
+}
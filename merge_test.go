@@ -0,0 +1,83 @@
+package tail
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMerge(t *testing.T) {
+	t.Run("with timestamps", func(t *testing.T) {
+		a := New(3, WithTimestamps())
+		b := New(3, WithTimestamps())
+		writes := []struct {
+			tb   *TailBuffer
+			data string
+		}{
+			{a, "a1\n"},
+			{b, "b1\n"},
+			{a, "a2\n"},
+			{b, "b2\nb"},
+			{a, "a3\n"},
+		}
+		for _, w := range writes {
+			if _, err := w.tb.Write([]byte(w.data)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		merged := Merge(4, a, b)
+		if expected, result := []string{"a2", "b2", "a3", "b"}, merged.Lines(); !slices.Equal(result, expected) {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+		if expected, result := "a2\nb2\na3\nb\n", merged.String(); result != expected {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	})
+
+	t.Run("with clock", func(t *testing.T) {
+		now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		clock := func() time.Time {
+			now = now.Add(time.Second)
+			return now
+		}
+		a := New(3, WithTimestamps(), WithClock(clock))
+		b := New(3, WithTimestamps(), WithClock(clock))
+		for _, tb := range []*TailBuffer{b, a, b, a} {
+			if _, err := tb.Write([]byte("x\n")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if _, err := a.Write([]byte("a")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// The pending line of a is timestamped by its clock when merged, after the lines of b
+		merged := Merge(5, a, b)
+		if expected, result := []string{"x", "x", "x", "x", "a"}, merged.Lines(); !slices.Equal(result, expected) {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	})
+
+	t.Run("without timestamps", func(t *testing.T) {
+		a := New(3)
+		b := New(3, WithTimestamps())
+		if _, err := a.Write([]byte("a1\na2\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := b.Write([]byte("b1\nb2\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		merged := Merge(3, b, a)
+		if expected, result := []string{"b2", "a1", "a2"}, merged.Lines(); !slices.Equal(result, expected) {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	})
+
+	t.Run("no buffers", func(t *testing.T) {
+		if result := Merge(3).Lines(); len(result) != 0 {
+			t.Errorf("expected no lines, got %q", result)
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package tail
+
+import (
+	"time"
+	"unsafe"
+)
+
+// lineMeta holds the optional metadata of the maintained lines in side slices parallel to tb.lines,
+// so that the maintained lines stay small when no option recording metadata is set.
+// Each slice is nil until a line with a non-zero value of it is maintained; then it has the same length as tb.lines.
+type lineMeta struct {
+	// seqs holds the sequence IDs recorded with WithSequenceIDs, or restored by Decode.
+	seqs []uint64
+	// times holds the times recorded with WithTimestamps.
+	times []time.Time
+	// tags holds the tags written with TaggedBuffer.
+	tags []string
+	// scopes holds the scope keys set with WithScopeKey.
+	scopes []string
+	// used reports whether any of the slices is allocated, to skip them quickly otherwise.
+	used bool
+}
+
+// push appends the metadata of l, where n is the number of lines maintained before l.
+func (m *lineMeta) push(l *line, n int) {
+	if m.used || l.seq != 0 || !l.time.IsZero() || l.tag != "" || l.scope != "" {
+		m.pushSlow(l, n)
+	}
+}
+
+func (m *lineMeta) pushSlow(l *line, n int) {
+	m.used = true
+	if m.seqs != nil || l.seq != 0 {
+		m.seqs = pushMeta(m.seqs, l.seq, n)
+	}
+	if m.times != nil || !l.time.IsZero() {
+		m.times = pushMeta(m.times, l.time, n)
+	}
+	if m.tags != nil || l.tag != "" {
+		m.tags = pushMeta(m.tags, l.tag, n)
+	}
+	if m.scopes != nil || l.scope != "" {
+		m.scopes = pushMeta(m.scopes, l.scope, n)
+	}
+}
+
+// drop removes the metadata of the oldest n lines.
+func (m *lineMeta) drop(n int) {
+	if m.used {
+		m.dropSlow(n)
+	}
+}
+
+func (m *lineMeta) dropSlow(n int) {
+	m.seqs = dropMeta(m.seqs, n)
+	m.times = dropMeta(m.times, n)
+	m.tags = dropMeta(m.tags, n)
+	m.scopes = dropMeta(m.scopes, n)
+}
+
+// truncate keeps the metadata of the oldest n lines.
+func (m *lineMeta) truncate(n int) {
+	if m.used {
+		m.truncateSlow(n)
+	}
+}
+
+func (m *lineMeta) truncateSlow(n int) {
+	m.seqs = truncateMeta(m.seqs, n)
+	m.times = truncateMeta(m.times, n)
+	m.tags = truncateMeta(m.tags, n)
+	m.scopes = truncateMeta(m.scopes, n)
+}
+
+// fill sets the metadata of the i-th line to l.
+func (m *lineMeta) fill(l *line, i int) {
+	if m.seqs != nil {
+		l.seq = m.seqs[i]
+	}
+	if m.times != nil {
+		l.time = m.times[i]
+	}
+	if m.tags != nil {
+		l.tag = m.tags[i]
+	}
+	if m.scopes != nil {
+		l.scope = m.scopes[i]
+	}
+}
+
+// seq returns the sequence ID of the i-th line, or 0 if it has none.
+func (m *lineMeta) seq(i int) uint64 {
+	if m.seqs == nil {
+		return 0
+	}
+	return m.seqs[i]
+}
+
+// size returns the number of bytes of metadata per line.
+func (m *lineMeta) size() int {
+	size := 0
+	if m.seqs != nil {
+		size += int(unsafe.Sizeof(m.seqs[0]))
+	}
+	if m.times != nil {
+		size += int(unsafe.Sizeof(m.times[0]))
+	}
+	if m.tags != nil {
+		size += int(unsafe.Sizeof(m.tags[0]))
+	}
+	if m.scopes != nil {
+		size += int(unsafe.Sizeof(m.scopes[0]))
+	}
+	return size
+}
+
+// pushMeta appends v to s, where n is the number of lines maintained before v.
+// s is allocated only once a non-zero value is pushed.
+func pushMeta[T comparable](s []T, v T, n int) []T {
+	var zero T
+	if s == nil {
+		if v == zero {
+			return nil
+		}
+		s = make([]T, n, n+1)
+	}
+	return append(s, v)
+}
+
+// dropMeta removes the first n values of s.
+func dropMeta[T any](s []T, n int) []T {
+	if s == nil {
+		return nil
+	}
+	clear(s[:n])
+	return s[n:]
+}
+
+// truncateMeta keeps the first n values of s.
+func truncateMeta[T any](s []T, n int) []T {
+	if s == nil {
+		return nil
+	}
+	clear(s[n:])
+	return s[:n]
+}
@@ -0,0 +1,55 @@
+package tail
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTailBuffer_LineMeta(t *testing.T) {
+	t.Run("not allocated by default", func(t *testing.T) {
+		tw := New(2)
+		if _, err := tw.Write([]byte("a\nb\nc\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if tw.meta.used {
+			t.Errorf("expected no metadata, got %+v", tw.meta)
+		}
+	})
+
+	t.Run("allocated on the first tag", func(t *testing.T) {
+		tw := NewTagged(3)
+		for _, w := range []struct{ data, tag string }{
+			{"a\n", ""},
+			{"b\n", ""},
+			{"c\n", "x"},
+			{"d\n", ""},
+			{"e\n", "y"},
+		} {
+			if _, err := tw.WriteTagged([]byte(w.data), w.tag); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		expected := []TaggedLine{{Tag: "x", Line: "c"}, {Tag: "", Line: "d"}, {Tag: "y", Line: "e"}}
+		if result := tw.TaggedLines(); !slices.Equal(result, expected) {
+			t.Errorf("expected %v, got %v", expected, result)
+		}
+		if len(tw.meta.tags) != len(tw.lines) || tw.meta.seqs != nil {
+			t.Errorf("expected only tags for %d lines, got %+v", len(tw.lines), tw.meta)
+		}
+	})
+
+	t.Run("cleared with the lines", func(t *testing.T) {
+		tw := New(3, WithSequenceIDs())
+		if _, err := tw.Write([]byte("a\nb\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		tw.Reset()
+		if _, err := tw.Write([]byte("c\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := []SequencedLine{{Seq: 3, Line: "c"}}
+		if result := tw.SequencedLines(); !slices.Equal(result, expected) {
+			t.Errorf("expected %v, got %v", expected, result)
+		}
+	})
+}
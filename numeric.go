@@ -0,0 +1,120 @@
+package tail
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// NumericBuffer maintains the last N numeric values written one per line, for rolling statistics of simple metrics.
+type NumericBuffer struct {
+	tb        *TailBuffer
+	mu        sync.Mutex
+	maxValues int
+	values    []float64
+	skipped   int
+}
+
+// NewNumeric creates a new NumericBuffer maintaining the last maxValues values parsed from the completed lines.
+// Leading and trailing white space of a line is ignored, and lines that are not a number are skipped.
+// opts configure how written data is split into lines, e.g. WithDelimiters.
+// A negative maxValues is treated as 0.
+func NewNumeric(maxValues int, opts ...Option) *NumericBuffer {
+	nb := &NumericBuffer{
+		tb:        New(1, opts...),
+		maxValues: max(maxValues, 0),
+	}
+
+	nb.tb.mu.Lock()
+	nb.tb.subscribe(nb)
+	nb.tb.mu.Unlock()
+	return nb
+}
+
+// Write implements the io.Writer interface.
+// It writes data and parses each completed line as a number.
+func (nb *NumericBuffer) Write(p []byte) (n int, err error) {
+	return nb.tb.Write(p)
+}
+
+// Values returns the maintained values, the oldest first.
+func (nb *NumericBuffer) Values() []float64 {
+	nb.mu.Lock()
+	defer nb.mu.Unlock()
+
+	return append([]float64{}, nb.values...)
+}
+
+// Mean returns the mean of the maintained values, or NaN if there are none.
+func (nb *NumericBuffer) Mean() float64 {
+	nb.mu.Lock()
+	defer nb.mu.Unlock()
+
+	if len(nb.values) == 0 {
+		return math.NaN()
+	}
+	var sum float64
+	for _, v := range nb.values {
+		sum += v
+	}
+	return sum / float64(len(nb.values))
+}
+
+// Min returns the minimum of the maintained values, or NaN if there are none.
+func (nb *NumericBuffer) Min() float64 {
+	nb.mu.Lock()
+	defer nb.mu.Unlock()
+
+	if len(nb.values) == 0 {
+		return math.NaN()
+	}
+	result := nb.values[0]
+	for _, v := range nb.values[1:] {
+		result = math.Min(result, v)
+	}
+	return result
+}
+
+// Max returns the maximum of the maintained values, or NaN if there are none.
+func (nb *NumericBuffer) Max() float64 {
+	nb.mu.Lock()
+	defer nb.mu.Unlock()
+
+	if len(nb.values) == 0 {
+		return math.NaN()
+	}
+	result := nb.values[0]
+	for _, v := range nb.values[1:] {
+		result = math.Max(result, v)
+	}
+	return result
+}
+
+// Skipped returns the number of completed lines skipped because they are not a number.
+func (nb *NumericBuffer) Skipped() int {
+	nb.mu.Lock()
+	defer nb.mu.Unlock()
+
+	return nb.skipped
+}
+
+// push parses a completed line and maintains its value.
+func (nb *NumericBuffer) push(text string) {
+	nb.mu.Lock()
+	defer nb.mu.Unlock()
+
+	v, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil {
+		nb.skipped++
+		return
+	}
+	if nb.maxValues == 0 {
+		return
+	}
+	if len(nb.values) == nb.maxValues {
+		copy(nb.values, nb.values[1:])
+		nb.values = nb.values[:len(nb.values)-1]
+	}
+	nb.values = append(nb.values, v)
+}
@@ -0,0 +1,78 @@
+package tail
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestNumericBuffer(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int
+		writes  []string
+		values  []float64
+		mean    float64
+		min     float64
+		max     float64
+		skipped int
+	}{
+		{
+			name:    "last values",
+			limit:   3,
+			writes:  []string{"1\n2\n", "3.5\n-1", "0\n"},
+			values:  []float64{2, 3.5, -10},
+			mean:    -1.5,
+			min:     -10,
+			max:     3.5,
+			skipped: 0,
+		},
+		{
+			name:    "non-numeric lines are skipped",
+			limit:   3,
+			writes:  []string{" 4 \nN/A\n\n6\n"},
+			values:  []float64{4, 6},
+			mean:    5,
+			min:     4,
+			max:     6,
+			skipped: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nb := NewNumeric(tt.limit)
+			for _, data := range tt.writes {
+				if _, err := nb.Write([]byte(data)); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			if result := nb.Values(); !slices.Equal(result, tt.values) {
+				t.Errorf("expected %v, got %v", tt.values, result)
+			}
+			if result := nb.Mean(); result != tt.mean {
+				t.Errorf("expected %v, got %v", tt.mean, result)
+			}
+			if result := nb.Min(); result != tt.min {
+				t.Errorf("expected %v, got %v", tt.min, result)
+			}
+			if result := nb.Max(); result != tt.max {
+				t.Errorf("expected %v, got %v", tt.max, result)
+			}
+			if result := nb.Skipped(); result != tt.skipped {
+				t.Errorf("expected %d, got %d", tt.skipped, result)
+			}
+		})
+	}
+
+	t.Run("no values", func(t *testing.T) {
+		nb := NewNumeric(3)
+		if result := nb.Mean(); !math.IsNaN(result) {
+			t.Errorf("expected NaN, got %v", result)
+		}
+		if result := nb.Values(); result == nil || len(result) != 0 {
+			t.Errorf("expected no values, got %v", result)
+		}
+	})
+}
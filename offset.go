@@ -0,0 +1,22 @@
+package tail
+
+// OffsetLine is a maintained line with its position in the written stream.
+type OffsetLine struct {
+	// Offset is the byte position in the written stream where the line begins.
+	Offset int64
+	Line   string
+}
+
+// OffsetLines returns the maintained lines with the byte positions where they begin in the written stream.
+// With WithDecoder, positions refer to the stream transcoded to UTF-8.
+func (tb *TailBuffer) OffsetLines() []OffsetLine {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	snapshot := tb.snapshot()
+	result := make([]OffsetLine, len(snapshot))
+	for i, l := range snapshot {
+		result[i] = OffsetLine{Offset: l.offset, Line: l.text}
+	}
+	return result
+}
@@ -0,0 +1,27 @@
+package tail
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTailBuffer_OffsetLines(t *testing.T) {
+	tw := New(3)
+	for _, data := range []string{"line1\n", "li", "ne2\r\n", "\nline4\nli"} {
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := tw.WriteByte('n'); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []OffsetLine{
+		{Offset: 13, Line: ""},
+		{Offset: 14, Line: "line4"},
+		{Offset: 20, Line: "lin"},
+	}
+	if result := tw.OffsetLines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
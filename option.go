@@ -0,0 +1,536 @@
+package tail
+
+import (
+	"encoding/binary"
+	"hash"
+	"io"
+	"math/bits"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"time"
+
+	"golang.org/x/text/encoding"
+)
+
+// Option is a functional option for TailBuffer.
+type Option func(*TailBuffer)
+
+// WithDecoder sets the encoding of incoming data.
+// Written bytes are transcoded to UTF-8 before line splitting, and a leading BOM is stripped.
+func WithDecoder(enc encoding.Encoding) Option {
+	return func(tb *TailBuffer) {
+		tb.decoder = enc.NewDecoder()
+	}
+}
+
+// WithDelimiterToken sets a multi-byte delimiter, such as "\r\n" or "<EOR>", that terminates a line.
+// It takes precedence over WithDelimiters and is used by String to rejoin lines.
+// A delimiter split across several writes is detected once its last byte is written.
+func WithDelimiterToken(token string) Option {
+	return func(tb *TailBuffer) {
+		tb.delimiterToken = token
+	}
+}
+
+// WithContinuation sets a predicate that reports whether a line continues the previous one.
+// A continuation line is joined to the previous line with "\n" and is not counted against maxLines,
+// so multi-line events such as stack traces are maintained as a single line.
+// The pending line is joined to the previous line in the same way if it is a continuation line.
+func WithContinuation(fn func(line string) bool) Option {
+	return func(tb *TailBuffer) {
+		tb.continuation = fn
+	}
+}
+
+// WithOverflowMarker sets a marker that Lines and String prepend once older lines have been evicted,
+// e.g. "... (earlier output omitted) ...".
+// The marker is not counted against maxLines, is omitted when no lines are output,
+// and disappears after Reset or ResetOverflow.
+func WithOverflowMarker(text string) Option {
+	return func(tb *TailBuffer) {
+		tb.overflowMarker = text
+	}
+}
+
+// WithTimestamps records the time each line is completed, out-of-band of the line text.
+// Merge uses it to interleave lines of several TailBuffers in the order they were completed.
+func WithTimestamps() Option {
+	return func(tb *TailBuffer) {
+		tb.timestamps = true
+	}
+}
+
+// WithTimestampPrefix prepends the time each line is completed, formatted with layout, and a space to the line.
+// A line written in multiple chunks gets a single timestamp. Continuation lines are not prefixed.
+func WithTimestampPrefix(layout string) Option {
+	return func(tb *TailBuffer) {
+		tb.timestampPrefix = layout
+	}
+}
+
+// WithDelimiters sets the bytes that terminate a line. A line is completed on any of them.
+// The first delimiter is the primary one, which String uses to rejoin lines.
+// The default is "\n".
+func WithDelimiters(delims ...byte) Option {
+	return func(tb *TailBuffer) {
+		if len(delims) > 0 {
+			tb.delimiters = delims
+		}
+	}
+}
+
+// WithRequireUTF8 makes Write reject completed lines containing invalid UTF-8.
+// Such lines are dropped and Write returns ErrInvalidUTF8 after processing the rest of the data,
+// reporting all bytes as consumed.
+func WithRequireUTF8() Option {
+	return func(tb *TailBuffer) {
+		tb.requireUTF8 = true
+	}
+}
+
+// WithSeenFilter skips completed lines whose FNV-1a hash is already in seen, and adds the hashes of new lines to seen.
+// seen can be persisted and reloaded to avoid maintaining lines already seen in a previous run.
+// seen grows by one entry per distinct line; use WithBoundedSeenFilter to cap it.
+// seen is modified by Write and must not be accessed concurrently. If seen is nil, an empty set is used.
+func WithSeenFilter(seen map[uint64]struct{}) Option {
+	return func(tb *TailBuffer) {
+		tb.seen = newSeenFilter(seen, 0)
+	}
+}
+
+// WithBoundedSeenFilter is like WithSeenFilter, but keeps at most size hashes in seen.
+// When the limit is exceeded, the oldest hashes are removed; hashes initially in seen are removed first,
+// in ascending order of the hashes.
+func WithBoundedSeenFilter(seen map[uint64]struct{}, size int) Option {
+	return func(tb *TailBuffer) {
+		tb.seen = newSeenFilter(seen, size)
+	}
+}
+
+// WithExcludePending excludes the pending line, which is not terminated yet, from the output.
+// By default, the pending line is included as the last line by Lines, String, Bytes and other accessors.
+func WithExcludePending() Option {
+	return func(tb *TailBuffer) {
+		tb.excludePending = true
+	}
+}
+
+// WithSectionStart sets a predicate that reports whether a line starts a section, e.g. "==> step N".
+// When set, maxLines is the number of sections to maintain instead of the number of lines,
+// so that old lines are evicted a whole section at a time.
+func WithSectionStart(fn func(line string) bool) Option {
+	return func(tb *TailBuffer) {
+		tb.sectionStart = fn
+	}
+}
+
+// WithArena stores the texts of lines in shared chunks of chunkSize bytes instead of allocating each of them,
+// which reduces allocations and GC overhead for large windows.
+// A chunk is kept alive as long as any maintained line refers to it, so in the worst case
+// memory usage is maxLines * chunkSize. Lines longer than chunkSize/4 are allocated separately.
+func WithArena(chunkSize int) Option {
+	return func(tb *TailBuffer) {
+		if chunkSize > 0 {
+			tb.arena = &arena{size: chunkSize}
+		}
+	}
+}
+
+// WithCollapseRuns collapses each run of the same character longer than maxRun in completed lines
+// into maxRun copies followed by a marker "[+N]", where N is the number of omitted characters.
+// It bounds the memory used by decorative lines such as "----...". Runs are detected per character,
+// so multibyte characters are never split. RawBytes returns the collapsed lines.
+func WithCollapseRuns(maxRun int) Option {
+	return func(tb *TailBuffer) {
+		tb.collapseRuns = maxRun
+	}
+}
+
+// WithChecksum feeds all bytes written to h, so that Checksum returns the digest of the whole stream
+// even though only the last lines are maintained.
+func WithChecksum(h hash.Hash) Option {
+	return func(tb *TailBuffer) {
+		tb.checksum = h
+	}
+}
+
+// WithLengthHistogram enables tracking the length distribution of all completed lines,
+// which is available via LengthHistogram.
+func WithLengthHistogram() Option {
+	return func(tb *TailBuffer) {
+		tb.lengthHistogram = make([]int, bits.UintSize+1)
+	}
+}
+
+// WithResetOnMarker discards the maintained lines when a completed line matches fn, such as "--- RUN START ---",
+// so that only the lines since the latest marker are maintained. If keepMarker is true,
+// the marker is maintained as the first line. maxLines still applies to the lines since the marker.
+func WithResetOnMarker(fn func(line string) bool, keepMarker bool) Option {
+	return func(tb *TailBuffer) {
+		tb.resetOnMarker = fn
+		tb.keepMarker = keepMarker
+	}
+}
+
+// WithInitialBufferSize grows the buffer for the pending line to n bytes in advance, and preallocates
+// the space for as many maintained lines as n bytes can complete, up to maxLines, reducing reallocations
+// during warm-up, e.g. while a long line is written. Without it, the space for the maintained lines
+// is allocated on demand as they are written.
+func WithInitialBufferSize(n int) Option {
+	return func(tb *TailBuffer) {
+		if n <= 0 {
+			return
+		}
+		tb.buffer.Grow(n)
+		// A line takes at least one byte
+		if size := min(tb.maxLines, n); size > cap(tb.store) {
+			tb.lines = make([]storedLine, 0, size)
+			tb.store = tb.lines
+		}
+	}
+}
+
+// WithClearOnFormFeed discards the maintained lines when a form feed ("\f") is written, like a terminal clearing the screen,
+// so that only the content since the last form feed is maintained.
+// A form feed in the middle of a line also discards the part of the line before it; the rest of the line is kept.
+func WithClearOnFormFeed() Option {
+	return func(tb *TailBuffer) {
+		tb.clearOnFormFeed = true
+	}
+}
+
+// WithSpanMarkers sets predicates that report whether a line begins or ends a span, e.g. "BEGIN xyz" and "END xyz".
+// When set, only lines in spans are maintained, and maxLines is the number of complete spans to maintain
+// in addition to the span in progress, so that old lines are evicted a whole span at a time.
+// Spans cannot be nested; a line matching begin within a span is an ordinary line of the span.
+func WithSpanMarkers(begin, end func(line string) bool) Option {
+	return func(tb *TailBuffer) {
+		tb.spanBegin = begin
+		tb.spanEnd = end
+	}
+}
+
+// WithScopeKey sets a function that returns the key of the current scope, such as a request ID,
+// which is called on every write to tag the lines completed by it. The lines of each scope can be
+// retrieved with LinesForScope. The lines of all scopes share maxLines and are evicted in the order written.
+func WithScopeKey(fn func() string) Option {
+	return func(tb *TailBuffer) {
+		tb.scopeKey = fn
+	}
+}
+
+// WithSqueezeBlankLines collapses each run of consecutive blank lines into a single blank line, like `cat -s`,
+// so that floods of blank lines do not push out the other lines. Lines consisting only of whitespace are
+// treated as blank. Runs are tracked across writes.
+func WithSqueezeBlankLines() Option {
+	return func(tb *TailBuffer) {
+		tb.squeezeBlank = true
+	}
+}
+
+// WithMaxKeys limits a KeyedBuffer to maintaining lines for at most n keys.
+// When a line with a new key exceeds the limit, the lines of the key written least recently are dropped.
+// It has no effect on a TailBuffer.
+func WithMaxKeys(n int) Option {
+	return func(tb *TailBuffer) {
+		tb.maxKeys = n
+	}
+}
+
+// WithDrainOnClose makes Close send the maintained lines, including the pending line, to ch in order
+// and then close ch, so that the final lines are processed exactly once on shutdown.
+// Close waits for at most timeout in total for ch to receive the lines, so that it does not block forever
+// if the receiver is gone; a timeout of zero or less waits indefinitely.
+func WithDrainOnClose(ch chan<- string, timeout time.Duration) Option {
+	return func(tb *TailBuffer) {
+		tb.drainCh = ch
+		tb.drainTimeout = timeout
+	}
+}
+
+// WithJSONObjectSplit splits written data into top-level JSON objects and arrays instead of lines,
+// tracking their nesting while respecting strings and escapes, so that pretty-printed values spanning
+// multiple lines are maintained as one line each. Whitespace between values and any other bytes outside of objects
+// and arrays, such as top-level scalars, are discarded, and the delimiters are ignored.
+// Use JSONValues to get the values as json.RawMessage.
+func WithJSONObjectSplit() Option {
+	return func(tb *TailBuffer) {
+		tb.json = &jsonSplitter{}
+	}
+}
+
+// WithClock sets the function used to read the current time, such as for WithTimestamps, instead of time.Now.
+// It allows tests to control the time.
+func WithClock(now func() time.Time) Option {
+	return func(tb *TailBuffer) {
+		if now != nil {
+			tb.clock = now
+		}
+	}
+}
+
+// WithErrorTail maintains a secondary window of the last maxLines completed lines matching pred, such as error-level lines,
+// which is fed by the same writes and can be retrieved with ErrorLines. The two windows evict lines independently,
+// so a line can appear in both the maintained lines and the error tail.
+func WithErrorTail(pred func(line string) bool, maxLines int) Option {
+	return func(tb *TailBuffer) {
+		tb.errorTail = &subTail{pred: pred, maxLines: maxLines}
+	}
+}
+
+// WithAlwaysTrailingNewline makes String, Bytes and WriteTo always end with the delimiter when any line is maintained,
+// even if the data ended mid-line, so that the output does not depend on whether the last write completed a line.
+func WithAlwaysTrailingNewline() Option {
+	return func(tb *TailBuffer) {
+		tb.trailingNewline = true
+	}
+}
+
+// WithNamedTails maintains a secondary window for each name in tails, such as "errors" or "warnings",
+// of the last MaxLines completed lines matching its Pred, which can be retrieved with NamedLines.
+// Each line is evaluated against the predicates of all names in sorted order of the names,
+// so a line may be maintained by multiple named tails as well as by the main tail.
+func WithNamedTails(tails map[string]NamedTailConfig) Option {
+	return func(tb *TailBuffer) {
+		tb.namedTails = make(map[string]*subTail, len(tails))
+		tb.namedTailOrder = make([]string, 0, len(tails))
+		for name, c := range tails {
+			tb.namedTails[name] = &subTail{pred: c.Pred, maxLines: c.MaxLines}
+			tb.namedTailOrder = append(tb.namedTailOrder, name)
+		}
+		slices.Sort(tb.namedTailOrder)
+	}
+}
+
+// WithSequenceIDs records a sequence ID for each completed line, which is the total number of lines written
+// up to and including the line, so that lines can be ordered and deduplicated across buffers. It is not reset
+// by eviction or Reset, and is preserved by Encode and Decode. Use SequencedLines to get the lines with their IDs.
+func WithSequenceIDs() Option {
+	return func(tb *TailBuffer) {
+		tb.sequenceIDs = true
+	}
+}
+
+// WithMemoryCap limits the memory used by the maintained lines and the pending line to n bytes,
+// so that the footprint of the buffer has a hard upper bound regardless of the length of lines.
+// The footprint is accounted as the length of the pending line plus, for each completed line, its length,
+// the length of its terminator and a fixed overhead for its bookkeeping, which is the size of an internal struct
+// of about a hundred bytes.
+// When a write exceeds the cap, the oldest lines are evicted, and a line that does not fit by itself
+// or a longer pending line is truncated at a byte boundary. Memory used only transiently during a write,
+// the spare capacity of internal buffers and the state of other options are not accounted.
+func WithMemoryCap(n int) Option {
+	return func(tb *TailBuffer) {
+		tb.memoryCap = n
+	}
+}
+
+// WithChangesOnly maintains a completed line only if it differs from the line completed immediately before it,
+// whether or not that line is maintained, e.g. to keep only the changes of a sensor reporting the same value repeatedly.
+// Unlike WithSeenFilter, a line equal to an older, non-adjacent line is maintained.
+func WithChangesOnly() Option {
+	return func(tb *TailBuffer) {
+		tb.changesOnly = true
+	}
+}
+
+// WithMaxRunes limits the completed lines to n runes in total, evicting the oldest lines as needed,
+// for a size bound meaningful for text where the byte length and the display length differ a lot.
+// A line that has more than n runes by itself is truncated to n runes, without splitting a multibyte character.
+// The limit is applied as each line is completed, so the lines maintained do not depend on how the data is split into writes.
+// The pending line is not counted. When maxLines is also set, both limits apply, so the stricter one determines
+// the lines maintained.
+func WithMaxRunes(n int) Option {
+	return func(tb *TailBuffer) {
+		tb.maxRunes = n
+	}
+}
+
+// WithPassthrough forwards every write to w immediately and byte for byte, including partial lines,
+// before the data is split into lines, so the output can be streamed live while the tail is maintained.
+// Only the bytes accepted by w are maintained, and the error of w is returned by Write.
+func WithPassthrough(w io.Writer) Option {
+	return func(tb *TailBuffer) {
+		tb.passthrough = w
+	}
+}
+
+// WithSampling maintains only every nth completed line, starting with the first one, and drops the others,
+// so the lines span a longer time range for chatty sources.
+// Lines are sampled after being filtered by WithChangesOnly, WithRequireUTF8, WithSeenFilter
+// and WithSqueezeBlankLines, so the lines filtered out do not count toward n.
+// n of 1 or less maintains all lines.
+func WithSampling(n int) Option {
+	return func(tb *TailBuffer) {
+		if n > 1 {
+			tb.sampler = &sampler{every: n}
+		}
+	}
+}
+
+// WithSamplingRate is like WithSampling, but maintains each completed line with probability r,
+// using rnd, which returns a number in [0.0, 1.0), e.g. for deterministic tests.
+// If rnd is nil, rand.Float64 of math/rand/v2 is used.
+func WithSamplingRate(r float64, rnd func() float64) Option {
+	return func(tb *TailBuffer) {
+		if rnd == nil {
+			rnd = rand.Float64
+		}
+		tb.sampler = &sampler{rate: r, rand: rnd}
+	}
+}
+
+// WithPendingFlushInterval completes the pending line as is, without a delimiter, when no data has been written
+// for d, so that the last partial line of a stalled producer is maintained as a line, e.g. for interactive displays.
+// Data written later starts a new line. The quiet period is measured by the clock set by WithClock,
+// checked by a timer restarted on each write and stopped by Close.
+// It does not apply with WithJSONObjectSplit or WithLengthPrefixedFraming.
+func WithPendingFlushInterval(d time.Duration) Option {
+	return func(tb *TailBuffer) {
+		if d > 0 {
+			tb.pendingFlush = &pendingFlush{interval: d}
+		}
+	}
+}
+
+// WithWriteTracker records the call sites of Write, WriteTagged, WriteCounted and WriteByte, summarized by WriterStats,
+// as a debugging aid to find unexpected writers. Each write costs a stack lookup, so it is off by default.
+func WithWriteTracker() Option {
+	return func(tb *TailBuffer) {
+		tb.tracker()
+	}
+}
+
+// WithWriterLimit tracks the call sites of writes as WithWriteTracker does, and calls warn when more than
+// maxCallSites distinct call sites have written, e.g. 1 for a buffer meant for a single source.
+// warn is called once for each call site beyond maxCallSites, with the stats of all the call sites
+// as returned by WriterStats, after the lock is released by the write, so it may read the buffer.
+// If warn is nil, the option is ignored.
+func WithWriterLimit(maxCallSites int, warn func(stats []WriterStat)) Option {
+	return func(tb *TailBuffer) {
+		if warn == nil {
+			return
+		}
+		t := tb.tracker()
+		t.maxCallSites = maxCallSites
+		t.warn = warn
+	}
+}
+
+// WithStringInterning shares one allocation between identical texts of completed lines,
+// which saves memory and allocations for repetitive output. The interned strings are kept in a map
+// bounded to 4096 strings, which is cleared when full, so it cannot grow unbounded.
+// Lines returned by Lines are normal strings.
+func WithStringInterning() Option {
+	return func(tb *TailBuffer) {
+		tb.interner = &interner{m: map[string]string{}}
+	}
+}
+
+// WithLatencyTracking records the latency of each write, including the time waiting for the lock,
+// by the clock set by WithClock, for WriteLatency. It is off by default, as it reads the clock twice per write.
+func WithLatencyTracking() Option {
+	return func(tb *TailBuffer) {
+		tb.latency = &latencyReservoir{}
+	}
+}
+
+// WithEvictionCallback calls fn for each line evicted from the maintained lines, with the reason,
+// e.g. to count the lines evicted by each limit. fn is called in the order the lines are evicted,
+// after the lock is released by the write evicting them, so it may read the buffer, but must not write to it.
+// Lines not maintained in the first place, e.g. with maxLines of 0, or discarded by Reset are not reported.
+func WithEvictionCallback(fn func(ev EvictionEvent)) Option {
+	return func(tb *TailBuffer) {
+		tb.notifier().fn = fn
+	}
+}
+
+// WithLengthPrefixedFraming splits written data into records framed by a length prefix of sizeBytes bytes
+// in byteOrder, followed by that many bytes of payload, instead of lines, for binary protocols.
+// Partial frames are kept pending until the rest of them is written, and the last maxLines records are maintained.
+// Use Records to get the payloads as byte slices. sizeBytes must be 1, 2, 4 or 8; otherwise the option is ignored.
+func WithLengthPrefixedFraming(sizeBytes int, byteOrder binary.ByteOrder) Option {
+	return func(tb *TailBuffer) {
+		switch sizeBytes {
+		case 1, 2, 4, 8:
+			tb.frames = &frameSplitter{size: sizeBytes, order: byteOrder}
+		}
+	}
+}
+
+// WithKeepFirstLine keeps the first completed line separately as a caption, such as a command invocation or a header,
+// returned by FirstLine even after it is evicted or discarded by Reset. It is captured exactly once.
+// If prepend is true, String, Lines and the other accessors rendering the lines with the overflow marker
+// prepend it while it is not among the maintained lines.
+func WithKeepFirstLine(prepend bool) Option {
+	return func(tb *TailBuffer) {
+		tb.keepFirstLine = true
+		tb.prependFirst = prepend
+	}
+}
+
+// WithStartAfter discards the completed lines until one matches re, such as "BEGIN CAPTURE",
+// and maintains the subsequent lines as usual, trimming the preamble. If keepMarker is true,
+// the matching line is maintained too. The pending line is not included by accessors until the marker matches.
+// The marker is matched only once, and Reset does not restart waiting for it.
+func WithStartAfter(re *regexp.Regexp, keepMarker bool) Option {
+	return func(tb *TailBuffer) {
+		if tb.region == nil {
+			tb.region = &region{}
+		}
+		tb.region.start = re
+		tb.region.keepStart = keepMarker
+	}
+}
+
+// WithStopAfter freezes the maintained lines once a completed line matches re, such as "END CAPTURE":
+// Write still consumes all bytes but discards them, the pending line is no longer included by accessors,
+// and Stopped reports true. If keepMarker is true, the matching line is maintained.
+// With WithStartAfter, the stop marker is matched only after the start marker, so exactly one region
+// between the markers is maintained: the first region wins, and later start markers are ordinary lines.
+func WithStopAfter(re *regexp.Regexp, keepMarker bool) Option {
+	return func(tb *TailBuffer) {
+		if tb.region == nil {
+			tb.region = &region{}
+		}
+		tb.region.stop = re
+		tb.region.keepStop = keepMarker
+	}
+}
+
+// WithRedaction masks sensitive data, such as tokens or email addresses, by applying rules in order
+// to each completed line before it is maintained or passed to filters, sub-tails and subscribers.
+// The pending line is redacted when it is read, so a secret split across writes is masked once it is written in full.
+// Only the redacted texts are kept by WithStringInterning and WithArena.
+// Data forwarded by WithPassthrough and fed to WithChecksum is not redacted.
+func WithRedaction(rules []RedactionRule) Option {
+	return func(tb *TailBuffer) {
+		tb.redaction = slices.Clone(rules)
+	}
+}
+
+// WithShrinkPolicy sets which lines are dropped when the lines exceed maxLines, on writes, on SetMaxLines and on Truncate.
+// With DropNewest, the first maxLines lines are kept and later lines, including the pending line, are dropped.
+// It does not apply to WithSectionStart, WithSpanMarkers, WithMaxRunes and WithMemoryCap, which always drop the oldest lines.
+func WithShrinkPolicy(policy ShrinkPolicy) Option {
+	return func(tb *TailBuffer) {
+		tb.shrinkPolicy = policy
+	}
+}
+
+// WithFileRotation appends evicted lines to files in dir, named tail.000001.log, tail.000002.log and so on,
+// for a history on disk beyond the maintained lines. A new file is started every maxLinesPerFile lines,
+// and the oldest files beyond maxFiles are removed. Files left in dir, e.g. by a previous run, are kept:
+// the numbering continues from the highest of them, and they count toward maxFiles.
+// The files are written after the lock is released, buffered and flushed every second; Close flushes them
+// and returns the first error writing them, after which lines are no longer written.
+// If maxFiles or maxLinesPerFile is less than 1, the option is ignored.
+func WithFileRotation(dir string, maxFiles, maxLinesPerFile int) Option {
+	return func(tb *TailBuffer) {
+		if maxFiles < 1 || maxLinesPerFile < 1 {
+			return
+		}
+		tb.notifier().spill = &fileRotator{dir: dir, maxFiles: maxFiles, maxLinesPerFile: maxLinesPerFile}
+	}
+}
@@ -0,0 +1,54 @@
+package tail
+
+import "time"
+
+// pendingFlush is the state of WithPendingFlushInterval.
+type pendingFlush struct {
+	interval time.Duration
+	// lastWrite is the time of the last write by the clock.
+	lastWrite time.Time
+	timer     *time.Timer
+}
+
+// schedulePendingFlush records the time of a write and restarts the timer to flush the pending line,
+// or stops it if there is no pending line.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) schedulePendingFlush() {
+	pf := tb.pendingFlush
+	if pf == nil {
+		return
+	}
+	if tb.buffer.Len() == 0 || tb.json != nil || tb.frames != nil {
+		if pf.timer != nil {
+			pf.timer.Stop()
+		}
+		return
+	}
+	pf.lastWrite = tb.clock()
+	if pf.timer == nil {
+		pf.timer = time.AfterFunc(pf.interval, tb.flushPendingIfQuiet)
+		return
+	}
+	pf.timer.Reset(pf.interval)
+}
+
+// flushPendingIfQuiet completes the pending line if the interval has elapsed since the last write by the clock,
+// or restarts the timer for the rest of the interval otherwise.
+func (tb *TailBuffer) flushPendingIfQuiet() {
+	defer tb.deliverEvictions()
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	pf := tb.pendingFlush
+	if tb.closed || tb.frozen || tb.buffer.Len() == 0 || tb.json != nil || tb.frames != nil {
+		return
+	}
+	if elapsed := tb.clock().Sub(pf.lastWrite); elapsed < pf.interval {
+		pf.timer.Reset(pf.interval - elapsed)
+		return
+	}
+
+	l := newLine(tb.buffer.String(), "")
+	tb.buffer.Reset()
+	_ = tb.complete([]line{l}, tb.pendingTag)
+}
@@ -0,0 +1,70 @@
+package tail
+
+import (
+	"io"
+	"sync"
+)
+
+// prefixStrippingWriter is an io.Writer that removes a prefix from the start of each line written to a TailBuffer.
+type prefixStrippingWriter struct {
+	tb     *TailBuffer
+	prefix string
+	mu     sync.Mutex
+	// atStart reports whether the data written next is at the start of a line.
+	atStart bool
+	// matched is the number of bytes of the prefix matched at the start of the current line, held back until
+	// the whole prefix is matched.
+	matched int
+}
+
+// PrefixStrippingWriter returns an io.Writer that writes to tb, removing prefix from the start of each line if present,
+// e.g. to strip the indentation of the output of a subprocess. The prefix is matched only at the start of lines,
+// even if it is split across writes; the start of a line matching a part of the prefix is held back
+// until the rest of it is written.
+func (tb *TailBuffer) PrefixStrippingWriter(prefix string) io.Writer {
+	return &prefixStrippingWriter{tb: tb, prefix: prefix, atStart: tb.AtLineBoundary()}
+}
+
+// Write implements the io.Writer interface.
+func (w *prefixStrippingWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for len(p) > 0 {
+		if w.atStart && w.prefix != "" {
+			size := min(len(w.prefix)-w.matched, len(p))
+			if string(p[:size]) == w.prefix[w.matched:w.matched+size] {
+				w.matched += size
+				if w.matched == len(w.prefix) {
+					w.matched = 0
+					w.atStart = false
+				}
+				n += size
+				p = p[size:]
+				continue
+			}
+			// Not prefixed, so write the bytes held back
+			if _, err := w.tb.Write([]byte(w.prefix[:w.matched])); err != nil {
+				return n, err
+			}
+			w.matched = 0
+		}
+		w.atStart = false
+
+		i, size := indexDelimiter(w.tb, p)
+		if i < 0 {
+			_, err := w.tb.Write(p)
+			if err != nil {
+				return n, err
+			}
+			return n + len(p), nil
+		}
+		if _, err := w.tb.Write(p[:i+size]); err != nil {
+			return n, err
+		}
+		n += i + size
+		p = p[i+size:]
+		w.atStart = true
+	}
+	return n, nil
+}
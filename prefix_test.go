@@ -0,0 +1,88 @@
+package tail
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTailBuffer_PrefixStrippingWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		writes   []string
+		expected []string
+	}{
+		{
+			name:     "prefixed lines",
+			prefix:   "  | ",
+			writes:   []string{"  | line1\n  | line2\n"},
+			expected: []string{"line1", "line2"},
+		},
+		{
+			name:     "lines without the prefix",
+			prefix:   "  | ",
+			writes:   []string{"line1\n  |line2\n  | line3\n"},
+			expected: []string{"line1", "  |line2", "line3"},
+		},
+		{
+			name:     "prefix only at the start of lines",
+			prefix:   "> ",
+			writes:   []string{"> a > b\n"},
+			expected: []string{"a > b"},
+		},
+		{
+			name:     "writes split mid-line",
+			prefix:   "> ",
+			writes:   []string{">", " a", " > b\n>", "> c\n", "> ", "> d\n"},
+			expected: []string{"a > b", ">> c", "> d"},
+		},
+		{
+			name:     "line shorter than the prefix",
+			prefix:   "> ",
+			writes:   []string{">\n", "> x"},
+			expected: []string{">", "x"},
+		},
+		{
+			name:     "empty prefix",
+			prefix:   "",
+			writes:   []string{"a\nb"},
+			expected: []string{"a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(5)
+			w := tw.PrefixStrippingWriter(tt.prefix)
+			for _, data := range tt.writes {
+				n, err := w.Write([]byte(data))
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if n != len(data) {
+					t.Errorf("expected %d bytes written, got %d", len(data), n)
+				}
+			}
+
+			if result := tw.Lines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_PrefixStrippingWriter_PendingLine(t *testing.T) {
+	tw := New(5)
+	if _, err := tw.Write([]byte("pending > ")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w := tw.PrefixStrippingWriter("> ")
+	if _, err := w.Write([]byte("> a\n> b\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"pending > > a", "b"}
+	if result := tw.Lines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
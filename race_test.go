@@ -0,0 +1,278 @@
+package tail
+
+import (
+	"context"
+	"crypto/sha256"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestTailBuffer_ConcurrentAccess runs writes and reads concurrently to surface data races with `go test -race`.
+func TestTailBuffer_ConcurrentAccess(t *testing.T) {
+	const (
+		maxLines = 50
+		writers  = 8
+		writes   = 200
+	)
+	valid := regexp.MustCompile(`^w\d+-\d+$`)
+
+	tw := New(maxLines,
+		WithChecksum(sha256.New()),
+		WithTimestamps(),
+		WithLengthHistogram(),
+		WithErrorTail(func(line string) bool { return strings.HasSuffix(line, "0") }, 10),
+		WithNamedTails(map[string]NamedTailConfig{
+			"first": {Pred: func(line string) bool { return strings.HasPrefix(line, "w0-") }, MaxLines: 10},
+		}),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	follower := tw.Follower(ctx)
+	batches, unsubscribe := tw.SubscribeBatched(10, time.Millisecond)
+
+	var consumers sync.WaitGroup
+	consumers.Add(2)
+	go func() {
+		defer consumers.Done()
+		_, _ = io.Copy(io.Discard, follower)
+	}()
+	go func() {
+		defer consumers.Done()
+		for range batches {
+		}
+	}()
+
+	check := func(lines []string) {
+		if len(lines) > maxLines {
+			t.Errorf("expected at most %d lines, got %d", maxLines, len(lines))
+		}
+		for _, l := range lines {
+			if !valid.MatchString(l) {
+				t.Errorf("unexpected line %q", l)
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range writes {
+				data := []byte("w" + strconv.Itoa(i) + "-" + strconv.Itoa(j) + "\n")
+				if i%2 == 0 {
+					if _, err := tw.Write(data); err != nil {
+						t.Errorf("unexpected error: %v", err)
+					}
+					continue
+				}
+				if _, _, err := tw.WriteCounted(data); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}
+		}()
+	}
+
+	readers := []func(){
+		func() { check(tw.Lines()) },
+		func() { _ = tw.String() },
+		func() { _ = tw.Bytes() },
+		func() { _, _ = tw.WriteTo(io.Discard) },
+		func() { _, _ = tw.WriteToLines(io.Discard) },
+		func() { _ = tw.RawBytes() },
+		func() { _, _ = io.Copy(io.Discard, tw.NullSeparatedReader()) },
+		func() { _ = tw.OffsetLines() },
+		func() { _ = tw.Stats() },
+		func() { _ = tw.LengthHistogram() },
+		func() { _ = tw.Checksum() },
+		func() { _ = tw.Encode() },
+		func() { _ = tw.ErrorLines() },
+		func() { _ = tw.NamedLines("first") },
+		func() { _ = tw.GrepContext(valid, 1, 1) },
+		func() { _, _ = tw.SplitAt(1) },
+		func() { _ = Reduce(tw, 0, func(acc int, line string) int { return acc + len(line) }) },
+		func() { _ = Merge(maxLines, tw, tw) },
+		func() { tw.Grow(10) },
+	}
+	for _, read := range readers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					read()
+				}
+			}
+		}()
+	}
+
+	// Stop the readers once all writes are done
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		for tw.TotalLines() < writers*writes {
+			time.Sleep(time.Millisecond)
+		}
+		close(done)
+	}()
+	wg.Wait()
+
+	check(tw.Lines())
+	if result := tw.TotalLines(); result != writers*writes {
+		t.Errorf("expected %d lines written, got %d", writers*writes, result)
+	}
+	if result := tw.Len(); result != maxLines {
+		t.Errorf("expected %d lines, got %d", maxLines, result)
+	}
+
+	unsubscribe()
+	cancel()
+	consumers.Wait()
+	if err := tw.Close(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// TestKeyedBuffer_ConcurrentAccess runs writes and reads of a KeyedBuffer concurrently.
+func TestKeyedBuffer_ConcurrentAccess(t *testing.T) {
+	kb := NewKeyed(5, func(line string) string {
+		key, _, _ := strings.Cut(line, "-")
+		return key
+	}, WithMaxKeys(4))
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				if _, err := kb.Write([]byte("k" + strconv.Itoa(i) + "-" + strconv.Itoa(j) + "\n")); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				for _, key := range kb.Keys() {
+					if lines := kb.LinesFor(key); len(lines) > 5 {
+						t.Errorf("expected at most 5 lines, got %d", len(lines))
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if keys := kb.Keys(); len(keys) > 4 {
+		t.Errorf("expected at most 4 keys, got %q", keys)
+	}
+}
+
+// TestTailBuffer_ConcurrentReset checks that Reset never splits the data of a single Write.
+func TestTailBuffer_ConcurrentReset(t *testing.T) {
+	const (
+		writers = 4
+		writes  = 500
+	)
+	tw := New(writers * writes * 2)
+
+	check := func(lines []string) {
+		for i, l := range lines {
+			prefix, ok := strings.CutSuffix(l, "-b")
+			if !ok {
+				continue
+			}
+			if i == 0 || lines[i-1] != prefix+"-a" {
+				t.Errorf("line %q is not preceded by the line written with it", l)
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range writes {
+				prefix := "w" + strconv.Itoa(i) + "-" + strconv.Itoa(j)
+				if _, err := tw.Write([]byte(prefix + "-a\n" + prefix + "-b\n")); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}
+		}()
+	}
+	done := make(chan struct{})
+	var resetter sync.WaitGroup
+	resetter.Add(1)
+	go func() {
+		defer resetter.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				check(tw.Lines())
+				tw.Reset()
+			}
+		}
+	}()
+	wg.Wait()
+	close(done)
+	resetter.Wait()
+
+	check(tw.Lines())
+}
+
+// TestTailBuffer_ConcurrentSetMaxLines runs SetMaxLines concurrently with the subscriptions sized by maxLines.
+func TestTailBuffer_ConcurrentSetMaxLines(t *testing.T) {
+	tw := New(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	subscribers := []func(){
+		func() {
+			if err := tw.ServeSSE(ctx, httptest.NewRecorder()); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		},
+		func() {
+			_, unsubscribe := tw.SubscribeBatched(5, time.Millisecond)
+			unsubscribe()
+		},
+	}
+	for _, subscribe := range subscribers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					subscribe()
+				}
+			}
+		}()
+	}
+
+	for i := range 2000 {
+		tw.SetMaxLines(i%20 + 1)
+		if _, err := tw.Write([]byte("line" + strconv.Itoa(i) + "\n")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	close(done)
+	wg.Wait()
+}
@@ -0,0 +1,91 @@
+package tail
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// readChunkSize is the size of each read by ReadFromContext.
+const readChunkSize = 32 * 1024
+
+// readChunks holds the chunks read by ReadFromContext, reused across reads and calls.
+var readChunks = sync.Pool{
+	New: func() any {
+		p := make([]byte, readChunkSize)
+		return &p
+	},
+}
+
+// readResult is the result of a read by ReadFromContext.
+type readResult struct {
+	chunk *[]byte
+	n     int
+	err   error
+}
+
+// ReadFromContext writes data read from r until EOF or an error, like io.Copy, and returns the number of bytes written.
+// When ctx is done, it returns ctx.Err() right away, maintaining the data read so far.
+//
+// Since a Read cannot be interrupted, r is read in a separate goroutine. If ctx is done while a Read is blocked,
+// the goroutine is left until the Read returns, and the data it returns is discarded;
+// close r to release it, e.g. a net.Conn or the read end of a pipe.
+func (tb *TailBuffer) ReadFromContext(ctx context.Context, r io.Reader) (n int64, err error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	results := make(chan readResult)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			chunk := readChunks.Get().(*[]byte)
+			n, err := r.Read(*chunk)
+			select {
+			case results <- readResult{chunk: chunk, n: n, err: err}:
+			case <-done:
+				readChunks.Put(chunk)
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return n, ctx.Err()
+		case res := <-results:
+			if res.n > 0 {
+				// Write does not retain the data, so the chunk can be reused once it returns
+				written, err := tb.Write((*res.chunk)[:res.n])
+				n += int64(written)
+				if err != nil {
+					readChunks.Put(res.chunk)
+					return n, err
+				}
+			}
+			readChunks.Put(res.chunk)
+			if res.err == io.EOF {
+				return n, nil
+			}
+			if res.err != nil {
+				return n, res.err
+			}
+		}
+	}
+}
+
+// LoadFromContext replaces the maintained lines with data read from r, e.g. to reload the tail of a file:
+// it discards the lines as Reset does, then reads r as ReadFromContext does, returning ctx.Err() when ctx is done
+// and maintaining the data read so far. If ctx is already done, the lines are kept as they are.
+func (tb *TailBuffer) LoadFromContext(ctx context.Context, r io.Reader) (n int64, err error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	tb.Reset()
+	return tb.ReadFromContext(ctx, r)
+}
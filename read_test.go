@@ -0,0 +1,119 @@
+package tail
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTailBuffer_ReadFromContext(t *testing.T) {
+	t.Run("until EOF", func(t *testing.T) {
+		tw := New(2)
+		input := "line1\nline2\n" + strings.Repeat("x", readChunkSize) + "\npending"
+		n, err := tw.ReadFromContext(context.Background(), strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != int64(len(input)) {
+			t.Errorf("expected %d, got %d", len(input), n)
+		}
+		if expected, result := []string{strings.Repeat("x", readChunkSize), "pending"}, tw.Lines(); !slices.Equal(result, expected) {
+			t.Errorf("expected %d lines, got %d", len(expected), len(result))
+		}
+	})
+
+	t.Run("cancelled while the read blocks", func(t *testing.T) {
+		pr, pw := io.Pipe()
+		defer pr.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		tw := New(2)
+		go func() {
+			_, _ = pw.Write([]byte("line1\nline2"))
+			for tw.Len() < 2 {
+				time.Sleep(time.Millisecond)
+			}
+			cancel()
+		}()
+
+		n, err := tw.ReadFromContext(ctx, pr)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %v, got %v", context.Canceled, err)
+		}
+		if n != 11 {
+			t.Errorf("expected %d, got %d", 11, n)
+		}
+		if expected, result := []string{"line1", "line2"}, tw.Lines(); !slices.Equal(result, expected) {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		readErr := errors.New("read error")
+		tw := New(2)
+		_, err := tw.ReadFromContext(context.Background(), io.MultiReader(strings.NewReader("line1\n"), &errReader{err: readErr}))
+		if !errors.Is(err, readErr) {
+			t.Errorf("expected %v, got %v", readErr, err)
+		}
+		if expected, result := []string{"line1"}, tw.Lines(); !slices.Equal(result, expected) {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	})
+}
+
+func TestTailBuffer_LoadFromContext(t *testing.T) {
+	t.Run("replaces the lines", func(t *testing.T) {
+		tw := New(3)
+		if _, err := tw.Write([]byte("old1\nold2\npen")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		n, err := tw.LoadFromContext(context.Background(), strings.NewReader("line1\nline2\n"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != 12 {
+			t.Errorf("expected %d, got %d", 12, n)
+		}
+		if expected, result := []string{"line1", "line2"}, tw.Lines(); !slices.Equal(result, expected) {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	})
+
+	t.Run("context already done", func(t *testing.T) {
+		tw := New(3)
+		if _, err := tw.Write([]byte("old\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := tw.LoadFromContext(ctx, strings.NewReader("line1\n")); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %v, got %v", context.Canceled, err)
+		}
+		if expected, result := []string{"old"}, tw.Lines(); !slices.Equal(result, expected) {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	})
+}
+
+func BenchmarkTailBuffer_ReadFromContext(b *testing.B) {
+	input := strings.Repeat("This is a line of the input\n", 100)
+	tw := New(10)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := tw.ReadFromContext(context.Background(), strings.NewReader(input)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
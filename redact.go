@@ -0,0 +1,24 @@
+package tail
+
+import "regexp"
+
+// RedactionRule replaces the matches of Pattern in each line with Replacement,
+// which may refer to submatches like regexp.Regexp.ReplaceAllString, e.g. "$1***".
+type RedactionRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// redact applies the rules set by WithRedaction to s in order.
+func (tb *TailBuffer) redact(s string) string {
+	for _, r := range tb.redaction {
+		s = r.Pattern.ReplaceAllString(s, r.Replacement)
+	}
+	return s
+}
+
+// pendingText returns the pending line, redacted with the rules set by WithRedaction.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) pendingText() string {
+	return tb.redact(tb.buffer.String())
+}
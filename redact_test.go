@@ -0,0 +1,100 @@
+package tail
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTailBuffer_WithRedaction(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []RedactionRule
+		input    string
+		expected []string
+	}{
+		{
+			name: "capture groups",
+			rules: []RedactionRule{
+				{Pattern: regexp.MustCompile(`(token=)\w+`), Replacement: "${1}***"},
+				{Pattern: regexp.MustCompile(`\b(\d{4})\d{8}(\d{4})\b`), Replacement: "$1********$2"},
+			},
+			input:    "auth token=abc123 ok\ncard 4111111111111111\n",
+			expected: []string{"auth token=*** ok", "card 4111********1111"},
+		},
+		{
+			name: "overlapping matches are replaced in order",
+			rules: []RedactionRule{
+				{Pattern: regexp.MustCompile(`[\w.]+@[\w.]+`), Replacement: "<email>"},
+				{Pattern: regexp.MustCompile(`admin\S*`), Replacement: "<user>"},
+			},
+			input:    "from admin@example.com and admin\n",
+			expected: []string{"from <email> and <user>"},
+		},
+		{
+			name: "pending line",
+			rules: []RedactionRule{
+				{Pattern: regexp.MustCompile(`secret`), Replacement: "***"},
+			},
+			input:    "a secret\nanother secret",
+			expected: []string{"a ***", "another ***"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(3, WithRedaction(tt.rules), WithErrorTail(func(string) bool { return true }, 3))
+			if _, err := tw.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result := tw.Lines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+			// The lines are redacted before they reach the sub-tails
+			if expected, result := tt.expected[:strings.Count(tt.input, "\n")], tw.ErrorLines(); !slices.Equal(result, expected) {
+				t.Errorf("expected %q, got %q", expected, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_WithRedaction_Storage(t *testing.T) {
+	rules := []RedactionRule{{Pattern: regexp.MustCompile(`(token=)\w+`), Replacement: "${1}***"}}
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"interning", []Option{WithStringInterning()}},
+		{"arena", []Option{WithArena(1024)}},
+		{"interning and arena", []Option{WithStringInterning(), WithArena(1024)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(3, append([]Option{WithRedaction(rules)}, tt.opts...)...)
+			for _, data := range []string{"token=secret123\n", "a\ntoken=secret123\n", "token=secret"} {
+				if _, err := tw.Write([]byte(data)); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if expected, result := []string{"a", "token=***", "token=***"}, tw.Lines(); !slices.Equal(result, expected) {
+				t.Errorf("expected %q, got %q", expected, result)
+			}
+
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			// The unredacted texts are not kept for the lifetime of the buffer
+			if tw.interner != nil {
+				for s := range tw.interner.m {
+					if strings.Contains(s, "secret") {
+						t.Errorf("unexpected interned text %q", s)
+					}
+				}
+			}
+			if tw.arena != nil && strings.Contains(string(tw.arena.chunk), "secret") {
+				t.Errorf("unexpected text in the arena: %q", tw.arena.chunk)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package tail
+
+import (
+	"regexp"
+	"slices"
+)
+
+// region scopes the maintained lines to the lines between a start marker and a stop marker.
+type region struct {
+	start     *regexp.Regexp
+	keepStart bool
+	stop      *regexp.Regexp
+	keepStop  bool
+	started   bool
+	stopped   bool
+}
+
+// filterRegion drops the completed lines outside the region set by WithStartAfter and WithStopAfter.
+func (tb *TailBuffer) filterRegion(lines []line) []line {
+	r := tb.region
+	if r == nil {
+		return lines
+	}
+	return slices.DeleteFunc(lines, func(l line) bool {
+		switch {
+		case r.stopped:
+			return true
+		case r.start != nil && !r.started:
+			if r.start.MatchString(l.text) {
+				r.started = true
+				return !r.keepStart
+			}
+			return true
+		case r.stop != nil && r.stop.MatchString(l.text):
+			r.stopped = true
+			return !r.keepStop
+		}
+		return false
+	})
+}
+
+// Stopped reports whether a completed line has matched the stop marker set by WithStopAfter,
+// so that no more lines are maintained.
+func (tb *TailBuffer) Stopped() bool {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	return tb.region != nil && tb.region.stopped
+}
+
+// pendingHidden reports whether the pending line is excluded from the output,
+// by WithExcludePending, because it is outside the region, not started or already stopped,
+// or because there is no room for it with DropNewest. It must be called with tb.mu held.
+func (tb *TailBuffer) pendingHidden() bool {
+	return tb.excludePending || tb.region != nil && tb.region.outside() || tb.dropsNewest() && len(tb.lines) >= tb.maxLines
+}
+
+// outside reports whether the lines written now are outside the region: before the start marker or after the stop marker.
+func (r *region) outside() bool {
+	return r.stopped || r.start != nil && !r.started
+}
@@ -0,0 +1,113 @@
+package tail
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestTailBuffer_WithStartAfter(t *testing.T) {
+	begin := regexp.MustCompile(`^BEGIN CAPTURE$`)
+	end := regexp.MustCompile(`^END CAPTURE$`)
+	input := "preamble\nBEGIN CAPTURE\nline1\nBEGIN CAPTURE\nline2\nEND CAPTURE\nafter\nEND CAPTURE\npen"
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected []string
+		stopped  bool
+	}{
+		{
+			name:     "start marker excluded",
+			opts:     []Option{WithStartAfter(begin, false)},
+			expected: []string{"line1", "BEGIN CAPTURE", "line2", "END CAPTURE", "after", "END CAPTURE", "pen"},
+		},
+		{
+			name:     "start marker included",
+			opts:     []Option{WithStartAfter(begin, true)},
+			expected: []string{"BEGIN CAPTURE", "line1", "BEGIN CAPTURE", "line2", "END CAPTURE", "after", "END CAPTURE", "pen"},
+		},
+		{
+			name:     "stop marker only",
+			opts:     []Option{WithStopAfter(end, true)},
+			expected: []string{"preamble", "BEGIN CAPTURE", "line1", "BEGIN CAPTURE", "line2", "END CAPTURE"},
+			stopped:  true,
+		},
+		{
+			name:     "both markers",
+			opts:     []Option{WithStartAfter(begin, false), WithStopAfter(end, false)},
+			expected: []string{"line1", "BEGIN CAPTURE", "line2"},
+			stopped:  true,
+		},
+		{
+			name:     "start marker never seen",
+			opts:     []Option{WithStartAfter(regexp.MustCompile(`^never$`), true)},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(10, tt.opts...)
+			for _, data := range []string{input[:20], input[20:]} {
+				if _, err := tw.Write([]byte(data)); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if result := tw.Lines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+			if result := tw.Stopped(); result != tt.stopped {
+				t.Errorf("expected %v, got %v", tt.stopped, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_WithStartAfter_Pending(t *testing.T) {
+	tw := New(10, WithStartAfter(regexp.MustCompile(`^BEGIN CAPTURE$`), true))
+	if _, err := tw.Write([]byte("pre1\npre2\nBEGIN CAP")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result := tw.Lines(); len(result) != 0 {
+		t.Errorf("expected no lines, got %q", result)
+	}
+	if result := tw.String(); result != "" {
+		t.Errorf("expected %q, got %q", "", result)
+	}
+	if result := tw.Len(); result != 0 {
+		t.Errorf("expected %d, got %d", 0, result)
+	}
+
+	if _, err := tw.Write([]byte("TURE\nline1\npen")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected, result := []string{"BEGIN CAPTURE", "line1", "pen"}, tw.Lines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestTailBuffer_WithStopAfter(t *testing.T) {
+	tw := New(3, WithStopAfter(regexp.MustCompile(`^END$`), false))
+	writes := []string{"line1\nEND\n", "line2\n", "pending"}
+	for _, data := range writes {
+		n, err := tw.Write([]byte(data))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != len(data) {
+			t.Errorf("expected %d, got %d", len(data), n)
+		}
+	}
+
+	// The window is frozen at the marker
+	if expected, result := "line1\n", tw.String(); result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+	if !tw.Stopped() {
+		t.Error("expected stopped")
+	}
+	if New(3).Stopped() {
+		t.Error("expected not stopped")
+	}
+}
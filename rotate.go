@@ -0,0 +1,183 @@
+package tail
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotationFlushInterval is the interval at which lines spilled by WithFileRotation are flushed to the file.
+const rotationFlushInterval = time.Second
+
+// fileRotator appends evicted lines to a rotating set of files.
+type fileRotator struct {
+	dir             string
+	maxFiles        int
+	maxLinesPerFile int
+
+	mu sync.Mutex
+	// files is the paths of the files kept, oldest first.
+	files []string
+	seq   int
+	// scanned reports whether the files left in dir, e.g. by a previous process, have been looked up.
+	scanned bool
+	f       *os.File
+	w       *bufio.Writer
+	lines   int
+	timer   *time.Timer
+	closed  bool
+	// err is the first error writing the files, after which lines are no longer spilled.
+	err error
+}
+
+// write appends the lines of events, rotating the files as they fill up.
+func (r *fileRotator) write(events []EvictionEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed || r.err != nil || len(events) == 0 {
+		return
+	}
+	for _, ev := range events {
+		if r.f == nil || r.lines >= r.maxLinesPerFile {
+			if r.err = r.rotate(); r.err != nil {
+				return
+			}
+		}
+		if _, r.err = r.w.WriteString(ev.Line + "\n"); r.err != nil {
+			return
+		}
+		r.lines++
+	}
+	if r.timer == nil {
+		r.timer = time.AfterFunc(rotationFlushInterval, r.flush)
+	}
+}
+
+// rotate closes the current file, opens the next one and removes the oldest ones beyond maxFiles.
+// It must be called with r.mu held.
+func (r *fileRotator) rotate() error {
+	if err := r.closeFile(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return err
+	}
+	if !r.scanned {
+		if err := r.scan(); err != nil {
+			return err
+		}
+		r.scanned = true
+	}
+	r.seq++
+	path := filepath.Join(r.dir, fmt.Sprintf("tail.%06d.log", r.seq))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	r.f = f
+	r.w = bufio.NewWriter(f)
+	r.lines = 0
+	r.files = append(r.files, path)
+	for len(r.files) > r.maxFiles {
+		if err := os.Remove(r.files[0]); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		r.files = r.files[1:]
+	}
+	return nil
+}
+
+// scan adds the files left in dir to the files kept, so that the sequence continues from the highest one
+// instead of overwriting them, and the oldest of them are removed beyond maxFiles.
+// It must be called with r.mu held.
+func (r *fileRotator) scan() error {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return err
+	}
+	type file struct {
+		name string
+		seq  int
+	}
+	var files []file
+	for _, e := range entries {
+		if seq, ok := rotatedSeq(e.Name()); ok && e.Type().IsRegular() {
+			files = append(files, file{name: e.Name(), seq: seq})
+		}
+	}
+	slices.SortFunc(files, func(a, b file) int { return a.seq - b.seq })
+	for _, f := range files {
+		r.files = append(r.files, filepath.Join(r.dir, f.name))
+		r.seq = f.seq
+	}
+	return nil
+}
+
+// rotatedSeq returns the sequence number of a file named like the rotated files, such as tail.000001.log.
+func rotatedSeq(name string) (int, bool) {
+	digits, ok := strings.CutPrefix(name, "tail.")
+	if !ok {
+		return 0, false
+	}
+	if digits, ok = strings.CutSuffix(digits, ".log"); !ok || len(digits) < 6 {
+		return 0, false
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+	}
+	seq, err := strconv.Atoi(digits)
+	return seq, err == nil
+}
+
+// flush writes the buffered lines to the current file.
+func (r *fileRotator) flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.timer = nil
+	if r.w == nil || r.err != nil {
+		return
+	}
+	r.err = r.w.Flush()
+}
+
+// closeFile flushes and closes the current file, if any.
+// It must be called with r.mu held.
+func (r *fileRotator) closeFile() error {
+	if r.f == nil {
+		return nil
+	}
+	err := errors.Join(r.w.Flush(), r.f.Close())
+	r.f = nil
+	r.w = nil
+	return err
+}
+
+// close flushes and closes the current file, and returns the first error writing the files.
+func (r *fileRotator) close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return r.err
+	}
+	r.closed = true
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	if err := r.closeFile(); r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
@@ -0,0 +1,141 @@
+package tail
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestTailBuffer_WithFileRotation(t *testing.T) {
+	tests := []struct {
+		name            string
+		maxFiles        int
+		maxLinesPerFile int
+		data            string
+		expected        map[string]string
+	}{
+		{"no eviction", 2, 2, "a\nb\n", map[string]string{}},
+		{"single file", 2, 3, "a\nb\nc\nd\n", map[string]string{"tail.000001.log": "a\nb\n"}},
+		{"rotated", 3, 2, "a\nb\nc\nd\ne\nf\n", map[string]string{
+			"tail.000001.log": "a\nb\n",
+			"tail.000002.log": "c\nd\n",
+		}},
+		{"oldest removed", 2, 1, "a\nb\nc\nd\ne\nf\n", map[string]string{
+			"tail.000003.log": "c\n",
+			"tail.000004.log": "d\n",
+		}},
+		{"ignored", 0, 1, "a\nb\nc\nd\n", map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "history")
+			tw := New(2, WithFileRotation(dir, tt.maxFiles, tt.maxLinesPerFile))
+			if _, err := tw.Write([]byte(tt.data)); err != nil {
+				t.Fatal(err)
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]string{}
+			entries, err := os.ReadDir(dir)
+			if err != nil && !os.IsNotExist(err) {
+				t.Fatal(err)
+			}
+			for _, e := range entries {
+				b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+				if err != nil {
+					t.Fatal(err)
+				}
+				got[e.Name()] = string(b)
+			}
+			if len(got) != len(tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+			for name, content := range tt.expected {
+				if got[name] != content {
+					t.Errorf("expected %q in %s, got %q", content, name, got[name])
+				}
+			}
+		})
+	}
+}
+
+func TestTailBuffer_WithFileRotation_EvictionCallback(t *testing.T) {
+	dir := t.TempDir()
+	var evicted []string
+	tw := New(1,
+		WithFileRotation(dir, 1, 10),
+		WithEvictionCallback(func(ev EvictionEvent) { evicted = append(evicted, ev.Line) }),
+	)
+	if _, err := tw.Write([]byte("a\nb\nc\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"a", "b"}; !slices.Equal(evicted, expected) {
+		t.Errorf("expected %q, got %q", expected, evicted)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "tail.000001.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "a\nb\n"; string(b) != expected {
+		t.Errorf("expected %q, got %q", expected, string(b))
+	}
+}
+
+func TestTailBuffer_WithFileRotation_Error(t *testing.T) {
+	// A regular file where the directory is expected
+	dir := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(dir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tw := New(1, WithFileRotation(dir, 1, 10))
+	if _, err := tw.Write([]byte("a\nb\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err == nil {
+		t.Error("expected an error, got nil")
+	}
+}
+
+func TestTailBuffer_WithFileRotation_Restart(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"tail.000001.log", "tail.000002.log", "tail.0000xx.log", "other.log"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("old\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tw := New(1, WithFileRotation(dir, 3, 1))
+	if _, err := tw.Write([]byte("a\nb\nc\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	expected := []string{"other.log", "tail.000002.log", "tail.000003.log", "tail.000004.log", "tail.0000xx.log"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("expected %q, got %q", expected, names)
+	}
+	for name, content := range map[string]string{"tail.000002.log": "old\n", "tail.000003.log": "a\n", "tail.000004.log": "b\n"} {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != content {
+			t.Errorf("expected %q in %s, got %q", content, name, string(b))
+		}
+	}
+}
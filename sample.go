@@ -0,0 +1,41 @@
+package tail
+
+// sampler decides which of the completed lines are maintained.
+type sampler struct {
+	// every keeps every Nth line if positive.
+	every int
+	// rate is the probability of keeping a line if every is 0.
+	rate float64
+	rand func() float64
+	// count is the number of lines sampled so far.
+	count int
+	// dropped is the number of lines dropped.
+	dropped int
+}
+
+// keep reports whether the next line is maintained.
+func (s *sampler) keep() bool {
+	var kept bool
+	if s.every > 0 {
+		kept = s.count%s.every == 0
+	} else {
+		kept = s.rand() < s.rate
+	}
+	s.count++
+	if !kept {
+		s.dropped++
+	}
+	return kept
+}
+
+// SampledOut returns the number of completed lines dropped by WithSampling or WithSamplingRate.
+// They are still counted in TotalLines.
+func (tb *TailBuffer) SampledOut() int {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if tb.sampler == nil {
+		return 0
+	}
+	return tb.sampler.dropped
+}
@@ -0,0 +1,25 @@
+package tail
+
+// LinesForScope returns the maintained lines that were written in the scope with key,
+// as returned by the function set by WithScopeKey.
+// The pending line belongs to the scope of the last write.
+func (tb *TailBuffer) LinesForScope(key string) []string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	result := []string{}
+	for _, l := range tb.snapshot() {
+		if l.scope == key {
+			result = append(result, l.text)
+		}
+	}
+	return result
+}
+
+// scope returns the key of the current scope, or an empty string if WithScopeKey is not set.
+func (tb *TailBuffer) scope() string {
+	if tb.scopeKey == nil {
+		return ""
+	}
+	return tb.scopeKey()
+}
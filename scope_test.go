@@ -0,0 +1,89 @@
+package tail
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTailBuffer_LinesForScope(t *testing.T) {
+	type write struct {
+		scope string
+		data  string
+	}
+	tests := []struct {
+		name     string
+		limit    int
+		writes   []write
+		key      string
+		expected []string
+	}{
+		{
+			name:  "lines of the scope",
+			limit: 10,
+			writes: []write{
+				{"a", "a1\n"},
+				{"b", "b1\n"},
+				{"a", "a2\n"},
+			},
+			key:      "a",
+			expected: []string{"a1", "a2"},
+		},
+		{
+			name:  "global eviction",
+			limit: 2,
+			writes: []write{
+				{"a", "a1\n"},
+				{"b", "b1\n"},
+				{"b", "b2\n"},
+			},
+			key:      "a",
+			expected: []string{},
+		},
+		{
+			name:  "pending line belongs to the last write",
+			limit: 10,
+			writes: []write{
+				{"a", "a1\nmixed "},
+				{"b", "b1"},
+			},
+			key:      "b",
+			expected: []string{"mixed b1"},
+		},
+		{
+			name:  "unknown scope",
+			limit: 10,
+			writes: []write{
+				{"a", "a1\n"},
+			},
+			key:      "c",
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var current string
+			tb := New(tt.limit, WithScopeKey(func() string { return current }))
+			for _, w := range tt.writes {
+				current = w.scope
+				if _, err := tb.Write([]byte(w.data)); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if result := tb.LinesForScope(tt.key); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_LinesForScope_WithoutScopeKey(t *testing.T) {
+	tb := New(3)
+	if _, err := tb.Write([]byte("line1\nline2\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"line1", "line2"}
+	if result := tb.LinesForScope(""); !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
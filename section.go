@@ -0,0 +1,35 @@
+package tail
+
+// Sections returns the maintained lines grouped into sections.
+// A section consists of a line matching the predicate set by WithSectionStart and the following lines
+// up to the next match. Lines before the first match form a section of their own.
+// The pending line belongs to the last section.
+func (tb *TailBuffer) Sections() [][]string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	sections := [][]string{}
+	for i, l := range tb.snapshot() {
+		if i == 0 || l.sectionStart {
+			sections = append(sections, []string{})
+		}
+		sections[len(sections)-1] = append(sections[len(sections)-1], l.text)
+	}
+	return sections
+}
+
+// sectionEvictionIndex returns the index of the first line of the oldest section to keep,
+// so that at most maxLines sections are maintained.
+func (tb *TailBuffer) sectionEvictionIndex() int {
+	sections := 0
+	for i := len(tb.lines) - 1; i >= 0; i-- {
+		if !tb.lines[i].sectionStart {
+			continue
+		}
+		sections++
+		if sections == tb.maxLines {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,86 @@
+package tail
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTailBuffer_Sections(t *testing.T) {
+	isHeader := func(line string) bool {
+		return strings.HasPrefix(line, "==> ")
+	}
+
+	tests := []struct {
+		name          string
+		limit         int
+		writes        []string
+		expected      [][]string
+		expectedLines []string
+	}{
+		{
+			name:   "last sections",
+			limit:  2,
+			writes: []string{"==> step 1\na\nb\n", "==> step 2\nc\n==> step 3\nd\ne\nf\n"},
+			expected: [][]string{
+				{"==> step 2", "c"},
+				{"==> step 3", "d", "e", "f"},
+			},
+			expectedLines: []string{"==> step 2", "c", "==> step 3", "d", "e", "f"},
+		},
+		{
+			name:   "lines before the first section",
+			limit:  2,
+			writes: []string{"preamble\n==> step 1\na\n"},
+			expected: [][]string{
+				{"preamble"},
+				{"==> step 1", "a"},
+			},
+			expectedLines: []string{"preamble", "==> step 1", "a"},
+		},
+		{
+			name:   "lines before the first section are evicted",
+			limit:  2,
+			writes: []string{"preamble\n==> step 1\na\n==> step 2\n"},
+			expected: [][]string{
+				{"==> step 1", "a"},
+				{"==> step 2"},
+			},
+			expectedLines: []string{"==> step 1", "a", "==> step 2"},
+		},
+		{
+			name:   "pending line belongs to the last section",
+			limit:  1,
+			writes: []string{"==> step 1\na\n==> step 2\nb\n==> st"},
+			expected: [][]string{
+				{"==> step 2", "b", "==> st"},
+			},
+			expectedLines: []string{"==> step 2", "b", "==> st"},
+		},
+		{
+			name:          "empty buffer",
+			limit:         2,
+			writes:        nil,
+			expected:      [][]string{},
+			expectedLines: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(tt.limit, WithSectionStart(isHeader))
+			for _, data := range tt.writes {
+				if _, err := tw.Write([]byte(data)); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			if result := tw.Sections(); !slices.EqualFunc(result, tt.expected, slices.Equal) {
+				t.Errorf("Sections(): expected %q, got %q", tt.expected, result)
+			}
+			if result := tw.Lines(); !slices.Equal(result, tt.expectedLines) {
+				t.Errorf("Lines(): expected %q, got %q", tt.expectedLines, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package tail
+
+import (
+	"hash/fnv"
+	"maps"
+	"slices"
+)
+
+// seenFilter skips lines that have already been seen, identified by their FNV-1a hash.
+type seenFilter struct {
+	seen map[uint64]struct{}
+	// order holds the hashes in insertion order when the filter is bounded.
+	order []uint64
+	size  int
+	// ready reports whether the initial hashes have been prepared by prepare.
+	ready bool
+}
+
+// newSeenFilter creates a filter with the initial hashes in seen, which is not modified until the filter is used.
+func newSeenFilter(seen map[uint64]struct{}, size int) *seenFilter {
+	if seen == nil {
+		seen = map[uint64]struct{}{}
+	}
+	return &seenFilter{
+		seen: seen,
+		size: size,
+	}
+}
+
+// prepare orders the initial hashes and removes those exceeding size, on the first use of the filter.
+func (f *seenFilter) prepare() {
+	if f.ready {
+		return
+	}
+	f.ready = true
+	if f.size > 0 {
+		// The insertion order of the initial hashes is unknown, so sort them to evict them deterministically
+		f.order = slices.Sorted(maps.Keys(f.seen))
+		f.shrink()
+	}
+}
+
+// clone returns a copy of f, so that recording hashes does not affect f.
+func (f *seenFilter) clone() *seenFilter {
+	return &seenFilter{
+		seen:  maps.Clone(f.seen),
+		order: slices.Clone(f.order),
+		size:  f.size,
+		ready: f.ready,
+	}
+}
+
+// seenBefore reports whether s has been seen, and records it if not.
+func (f *seenFilter) seenBefore(s string) bool {
+	f.prepare()
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	sum := h.Sum64()
+	if _, ok := f.seen[sum]; ok {
+		return true
+	}
+	f.seen[sum] = struct{}{}
+	if f.size > 0 {
+		f.order = append(f.order, sum)
+		f.shrink()
+	}
+	return false
+}
+
+// shrink removes the oldest hashes exceeding size.
+func (f *seenFilter) shrink() {
+	for len(f.order) > f.size {
+		delete(f.seen, f.order[0])
+		f.order = f.order[1:]
+	}
+}
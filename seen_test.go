@@ -0,0 +1,78 @@
+package tail
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTailBuffer_WithSeenFilter(t *testing.T) {
+	seen := map[uint64]struct{}{}
+
+	// First run
+	tw := New(5, WithSeenFilter(seen))
+	if _, err := tw.Write([]byte("line1\nline2\nline1\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected, result := []string{"line1", "line2"}, tw.Lines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+	if len(seen) != 2 {
+		t.Errorf("expected 2 hashes, got %d", len(seen))
+	}
+
+	// Second run seeded with the hashes of the first run
+	tw = New(5, WithSeenFilter(seen))
+	if _, err := tw.Write([]byte("line2\nline3\nline1\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected, result := []string{"line3"}, tw.Lines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+	if len(seen) != 3 {
+		t.Errorf("expected 3 hashes, got %d", len(seen))
+	}
+}
+
+func TestTailBuffer_WithBoundedSeenFilter(t *testing.T) {
+	seen := map[uint64]struct{}{}
+	tw := New(10, WithBoundedSeenFilter(seen, 2))
+	if _, err := tw.Write([]byte("line1\nline2\nline3\nline1\nline3\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// line1 is forgotten when line3 is seen
+	if expected, result := []string{"line1", "line2", "line3", "line1"}, tw.Lines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+	if len(seen) != 2 {
+		t.Errorf("expected 2 hashes, got %d", len(seen))
+	}
+}
+
+func TestTailBuffer_WithSeenFilter_Nil(t *testing.T) {
+	tw := New(5, WithSeenFilter(nil))
+	if _, err := tw.Write([]byte("line1\nline1\nline2\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected, result := []string{"line1", "line2"}, tw.Lines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestTailBuffer_WithBoundedSeenFilter_Seeded(t *testing.T) {
+	for range 10 {
+		seen := map[uint64]struct{}{1: {}, 2: {}, 3: {}}
+		tw := New(10, WithBoundedSeenFilter(seen, 2))
+		if len(seen) != 3 {
+			t.Fatalf("expected seen not to be modified before writing, got %v", seen)
+		}
+		if _, err := tw.Write([]byte("a\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, ok1 := seen[1]
+		_, ok2 := seen[2]
+		if ok1 || ok2 || len(seen) != 2 {
+			t.Fatalf("expected the smallest hashes to be removed, got %v", seen)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package tail
+
+// SequencedLine is a completed line with its sequence ID.
+type SequencedLine struct {
+	// Seq is the number of lines written up to and including the line, which increases monotonically
+	// and is not affected by eviction.
+	Seq  uint64
+	Line string
+}
+
+// SequencedLines returns the completed lines with the sequence IDs recorded with WithSequenceIDs.
+// The sequence IDs are 0 for lines completed without WithSequenceIDs.
+func (tb *TailBuffer) SequencedLines() []SequencedLine {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	result := make([]SequencedLine, len(tb.lines))
+	for i, l := range tb.lines {
+		result[i] = SequencedLine{Seq: tb.meta.seq(i), Line: l.text}
+	}
+	return result
+}
@@ -0,0 +1,80 @@
+package tail
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTailBuffer_SequencedLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		writes   []string
+		expected []SequencedLine
+	}{
+		{
+			name:   "not reset by eviction",
+			opts:   []Option{WithSequenceIDs()},
+			writes: []string{"line1\nline2\n", "line3\nline4\npen"},
+			expected: []SequencedLine{
+				{Seq: 2, Line: "line2"},
+				{Seq: 3, Line: "line3"},
+				{Seq: 4, Line: "line4"},
+			},
+		},
+		{
+			name:   "filtered lines take IDs",
+			opts:   []Option{WithSequenceIDs(), WithSeenFilter(map[uint64]struct{}{})},
+			writes: []string{"a\nb\na\nc\n"},
+			expected: []SequencedLine{
+				{Seq: 1, Line: "a"},
+				{Seq: 2, Line: "b"},
+				{Seq: 4, Line: "c"},
+			},
+		},
+		{
+			name:   "without WithSequenceIDs",
+			writes: []string{"line1\n"},
+			expected: []SequencedLine{
+				{Seq: 0, Line: "line1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(3, tt.opts...)
+			for _, data := range tt.writes {
+				if _, err := tw.Write([]byte(data)); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			if result := tw.SequencedLines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_SequencedLines_Encode(t *testing.T) {
+	tw := New(2, WithSequenceIDs())
+	if _, err := tw.Write([]byte("line1\nline2\nline3\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	decoded, err := Decode(tw.Encode(), WithSequenceIDs())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := decoded.Write([]byte("line4\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []SequencedLine{
+		{Seq: 3, Line: "line3"},
+		{Seq: 4, Line: "line4"},
+	}
+	if result := decoded.SequencedLines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
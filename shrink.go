@@ -0,0 +1,96 @@
+package tail
+
+// ShrinkPolicy determines which lines are dropped when the lines exceed maxLines.
+type ShrinkPolicy int
+
+const (
+	// DropOldest drops the oldest lines, like `tail`. It is the default.
+	DropOldest ShrinkPolicy = iota
+	// DropNewest drops the newest lines, keeping the first lines like `head`, e.g. for a view pinned to the top.
+	DropNewest
+)
+
+// SetMaxLines changes the maximum number of lines. If the maintained lines exceed the new limit,
+// they are dropped according to the policy set by WithShrinkPolicy, the oldest ones by default,
+// and reported to the callback set by WithEvictionCallback. A negative maxLines is treated as 0.
+func (tb *TailBuffer) SetMaxLines(maxLines int) {
+	defer tb.deliverEvictions()
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if tb.frozen {
+		return
+	}
+	tb.maxLines = max(maxLines, 0)
+	if tb.maxLines == 0 {
+		if len(tb.lines) > 0 {
+			tb.recordEvictions(0, len(tb.lines), EvictedByMaxLines)
+			tb.overflowed = true
+			tb.evictedLines += len(tb.lines)
+		}
+		tb.lines = []storedLine{}
+		tb.meta = lineMeta{}
+		tb.lineBytes = 0
+		tb.termBytes = 0
+		tb.runes = 0
+		return
+	}
+	tb.evict()
+}
+
+// Truncate drops the maintained lines beyond the first or last n lines without changing maxLines.
+// The lines are dropped according to the policy set by WithShrinkPolicy, the oldest ones by default,
+// and reported to the callback set by WithEvictionCallback. A negative n is treated as 0.
+func (tb *TailBuffer) Truncate(n int) {
+	defer tb.deliverEvictions()
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if tb.frozen {
+		return
+	}
+	n = max(n, 0)
+	if tb.shrinkPolicy == DropNewest {
+		tb.dropNewest(n)
+		return
+	}
+	tb.dropOldest(len(tb.lines) - n)
+}
+
+// dropOldest drops the oldest n lines, if any.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) dropOldest(n int) {
+	if n <= 0 {
+		return
+	}
+	tb.recordEvictions(0, n, EvictedByMaxLines)
+	tb.forgetLines(tb.lines[:n])
+	// Release the evicted texts, which are otherwise still referenced by the backing array
+	clear(tb.lines[:n])
+	tb.lines = tb.lines[n:]
+	tb.meta.drop(n)
+	tb.overflowed = true
+	tb.evictedLines += n
+}
+
+// dropNewest drops the lines beyond the oldest n lines, if any.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) dropNewest(n int) {
+	excess := len(tb.lines) - n
+	if excess <= 0 {
+		return
+	}
+	tb.recordEvictions(n, len(tb.lines), EvictedByMaxLines)
+	tb.forgetLines(tb.lines[n:])
+	clear(tb.lines[n:])
+	tb.lines = tb.lines[:n]
+	tb.meta.truncate(n)
+	tb.overflowed = true
+	tb.evictedLines += excess
+}
+
+// dropsNewest reports whether the newest lines are dropped when the lines exceed maxLines.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) dropsNewest() bool {
+	return tb.shrinkPolicy == DropNewest && tb.sectionStart == nil && tb.spanBegin == nil && tb.maxLines > 0
+}
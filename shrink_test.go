@@ -0,0 +1,109 @@
+package tail
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTailBuffer_WithShrinkPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   ShrinkPolicy
+		data     string
+		expected []string
+	}{
+		{"drop oldest", DropOldest, "a\nb\nc\nd\n", []string{"b", "c", "d"}},
+		{"drop newest", DropNewest, "a\nb\nc\nd\n", []string{"a", "b", "c"}},
+		{"drop newest with room for pending", DropNewest, "a\nb\nc", []string{"a", "b", "c"}},
+		{"drop newest hides pending", DropNewest, "a\nb\nc\nd", []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(3, WithShrinkPolicy(tt.policy))
+			if _, err := tw.Write([]byte(tt.data)); err != nil {
+				t.Fatal(err)
+			}
+			if result := tw.Lines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_SetMaxLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   ShrinkPolicy
+		maxLines int
+		expected []string
+		evicted  []string
+	}{
+		{"shrink drop oldest", DropOldest, 2, []string{"c", "d"}, []string{"a", "b"}},
+		{"shrink drop newest", DropNewest, 2, []string{"a", "b"}, []string{"c", "d"}},
+		{"grow", DropOldest, 10, []string{"a", "b", "c", "d"}, nil},
+		{"zero", DropNewest, 0, []string{}, []string{"a", "b", "c", "d"}},
+		{"negative", DropOldest, -1, []string{}, []string{"a", "b", "c", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var evicted []string
+			tw := New(5, WithShrinkPolicy(tt.policy), WithEvictionCallback(func(e EvictionEvent) {
+				evicted = append(evicted, e.Line)
+			}))
+			if _, err := tw.Write([]byte("a\nb\nc\nd\n")); err != nil {
+				t.Fatal(err)
+			}
+			tw.SetMaxLines(tt.maxLines)
+			if result := tw.Lines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+			if !slices.Equal(evicted, tt.evicted) {
+				t.Errorf("expected %q, got %q", tt.evicted, evicted)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_Truncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   ShrinkPolicy
+		n        int
+		expected []string
+		evicted  []string
+	}{
+		{"drop oldest", DropOldest, 2, []string{"c", "d"}, []string{"a", "b"}},
+		{"drop newest", DropNewest, 2, []string{"a", "b"}, []string{"c", "d"}},
+		{"more than maintained", DropOldest, 10, []string{"a", "b", "c", "d"}, nil},
+		{"zero", DropNewest, 0, []string{}, []string{"a", "b", "c", "d"}},
+		{"negative", DropOldest, -1, []string{}, []string{"a", "b", "c", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var evicted []string
+			tw := New(5, WithShrinkPolicy(tt.policy), WithEvictionCallback(func(e EvictionEvent) {
+				evicted = append(evicted, e.Line)
+			}))
+			if _, err := tw.Write([]byte("a\nb\nc\nd\n")); err != nil {
+				t.Fatal(err)
+			}
+			tw.Truncate(tt.n)
+			if result := tw.Lines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+			if !slices.Equal(evicted, tt.evicted) {
+				t.Errorf("expected %q, got %q", tt.evicted, evicted)
+			}
+			if result := tw.RetainedBytes(); result != len(tt.expected) {
+				t.Errorf("expected %d, got %d", len(tt.expected), result)
+			}
+			// maxLines is unchanged
+			if _, err := tw.Write([]byte("e\nf\ng\nh\ni\nj\n")); err != nil {
+				t.Fatal(err)
+			}
+			if result := tw.Len(); result != 5 {
+				t.Errorf("expected %d, got %d", 5, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package tail
+
+// Spans returns the complete spans maintained, each consisting of the lines from a line matching
+// the begin predicate set by WithSpanMarkers to the following line matching the end predicate.
+func (tb *TailBuffer) Spans() [][]string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	spans := [][]string{}
+	for _, l := range tb.lines {
+		if l.spanBegin {
+			spans = append(spans, []string{})
+		}
+		if len(spans) > 0 {
+			spans[len(spans)-1] = append(spans[len(spans)-1], l.text)
+		}
+	}
+	if tb.inSpan && len(spans) > 0 {
+		spans = spans[:len(spans)-1]
+	}
+	return spans
+}
+
+// InProgressSpan returns the lines of the span that has begun but not ended yet, including the pending line.
+// It returns an empty slice if no span is in progress.
+func (tb *TailBuffer) InProgressSpan() []string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	span := []string{}
+	if !tb.inSpan {
+		return span
+	}
+	for i := len(tb.lines) - 1; i >= 0; i-- {
+		if tb.lines[i].spanBegin {
+			for _, l := range tb.lines[i:] {
+				span = append(span, l.text)
+			}
+			break
+		}
+	}
+	if tb.pendingSeparate() {
+		span = append(span, tb.pendingText())
+	} else if len(span) > 0 && tb.buffer.Len() > 0 && !tb.pendingHidden() {
+		span[len(span)-1] += "\n" + tb.pendingText()
+	}
+	return span
+}
+
+// spanEvictionIndex returns the index of the first line of the oldest span to keep,
+// so that at most maxLines complete spans and the span in progress are maintained.
+func (tb *TailBuffer) spanEvictionIndex() int {
+	keep := tb.maxLines
+	if tb.inSpan {
+		keep++
+	}
+	spans := 0
+	for i := len(tb.lines) - 1; i >= 0; i-- {
+		if !tb.lines[i].spanBegin {
+			continue
+		}
+		spans++
+		if spans == keep {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,70 @@
+package tail
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTailBuffer_Spans(t *testing.T) {
+	begin := func(line string) bool { return strings.HasPrefix(line, "BEGIN ") }
+	end := func(line string) bool { return strings.HasPrefix(line, "END ") }
+
+	tests := []struct {
+		name               string
+		limit              int
+		input              string
+		expectedSpans      [][]string
+		expectedInProgress []string
+		expectedLines      []string
+	}{
+		{
+			name:  "last complete spans",
+			limit: 2,
+			input: "BEGIN a\n1\nEND a\nnoise\nBEGIN b\n2\nEND b\nBEGIN c\nEND c\n",
+			expectedSpans: [][]string{
+				{"BEGIN b", "2", "END b"},
+				{"BEGIN c", "END c"},
+			},
+			expectedInProgress: []string{},
+			expectedLines:      []string{"BEGIN b", "2", "END b", "BEGIN c", "END c"},
+		},
+		{
+			name:  "span in progress",
+			limit: 1,
+			input: "BEGIN a\nEND a\nBEGIN b\nEND b\nBEGIN c\n3\npen",
+			expectedSpans: [][]string{
+				{"BEGIN b", "END b"},
+			},
+			expectedInProgress: []string{"BEGIN c", "3", "pen"},
+			expectedLines:      []string{"BEGIN b", "END b", "BEGIN c", "3", "pen"},
+		},
+		{
+			name:               "no spans",
+			limit:              1,
+			input:              "noise\nnoise\n",
+			expectedSpans:      [][]string{},
+			expectedInProgress: []string{},
+			expectedLines:      []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(tt.limit, WithSpanMarkers(begin, end))
+			if _, err := tw.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result := tw.Spans(); !slices.EqualFunc(result, tt.expectedSpans, slices.Equal) {
+				t.Errorf("Spans(): expected %q, got %q", tt.expectedSpans, result)
+			}
+			if result := tw.InProgressSpan(); !slices.Equal(result, tt.expectedInProgress) {
+				t.Errorf("InProgressSpan(): expected %q, got %q", tt.expectedInProgress, result)
+			}
+			if result := tw.Lines(); !slices.Equal(result, tt.expectedLines) {
+				t.Errorf("Lines(): expected %q, got %q", tt.expectedLines, result)
+			}
+		})
+	}
+}
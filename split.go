@@ -0,0 +1,30 @@
+package tail
+
+// SplitLines splits data into lines exactly as Write of a TailBuffer created with opts does,
+// e.g. to pre-process data consistently with a buffer. It returns the completed lines
+// and the trailing incomplete line, which is the pending line of the buffer.
+// Options filtering or transforming lines, such as WithSeenFilter, apply as well, but SplitLines
+// has no effect outside of it: the seen set of WithSeenFilter is not updated, and WithChecksum,
+// WithPassthrough, WithDrainOnClose, WithEvictionCallback, WithFileRotation and WithPendingFlushInterval are ignored.
+func SplitLines(data []byte, opts ...Option) (lines []string, rest string) {
+	// Every line fits, as a line takes at least one byte
+	tb := New(max(len(data), 1), opts...)
+	tb.checksum = nil
+	tb.passthrough = nil
+	tb.drainCh = nil
+	tb.evictions = nil
+	tb.pendingFlush = nil
+	if tb.seen != nil {
+		tb.seen = tb.seen.clone()
+	}
+	_, _ = tb.Write(data)
+
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	lines = make([]string, len(tb.lines))
+	for i, l := range tb.lines {
+		lines[i] = l.text
+	}
+	return lines, tb.pendingText()
+}
@@ -0,0 +1,119 @@
+package tail
+
+import (
+	"bytes"
+	"hash/fnv"
+	"maps"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		opts     []Option
+		expected []string
+		rest     string
+	}{
+		{
+			name:     "CRLF and empty lines",
+			data:     "line1\r\n\nline2\nli",
+			expected: []string{"line1", "", "line2"},
+			rest:     "li",
+		},
+		{
+			name:     "no incomplete line",
+			data:     "line1\n",
+			expected: []string{"line1"},
+			rest:     "",
+		},
+		{
+			name:     "empty",
+			data:     "",
+			expected: []string{},
+			rest:     "",
+		},
+		{
+			name:     "delimiter token",
+			data:     "a<>b<>c",
+			opts:     []Option{WithDelimiterToken("<>")},
+			expected: []string{"a", "b"},
+			rest:     "c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, rest := SplitLines([]byte(tt.data), tt.opts...)
+			if !slices.Equal(lines, tt.expected) || lines == nil {
+				t.Errorf("expected %q, got %q", tt.expected, lines)
+			}
+			if rest != tt.rest {
+				t.Errorf("expected %q, got %q", tt.rest, rest)
+			}
+
+			// The lines match those of a buffer
+			tw := New(10, tt.opts...)
+			if _, err := tw.Write([]byte(tt.data)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if expected, result := append(slices.Clone(lines), rest)[:tw.Len()], tw.Lines(); !slices.Equal(result, expected) {
+				t.Errorf("expected %q, got %q", expected, result)
+			}
+		})
+	}
+}
+
+func TestSplitLines_NoSideEffects(t *testing.T) {
+	seen := map[uint64]struct{}{}
+	h := fnv.New64a()
+	var passthrough bytes.Buffer
+	ch := make(chan string, 10)
+	dir := t.TempDir()
+	evicted := 0
+	lines, rest := SplitLines([]byte("a\na\nb\nc"),
+		WithSeenFilter(seen),
+		WithChecksum(h),
+		WithPassthrough(&passthrough),
+		WithDrainOnClose(ch, time.Second),
+		WithEvictionCallback(func(EvictionEvent) { evicted++ }),
+		WithFileRotation(dir, 1, 1),
+		WithMaxRunes(1),
+		WithPendingFlushInterval(time.Millisecond),
+	)
+	if expected := []string{"b"}; !slices.Equal(lines, expected) {
+		t.Errorf("expected %q, got %q", expected, lines)
+	}
+	if rest != "c" {
+		t.Errorf("expected %q, got %q", "c", rest)
+	}
+	if len(seen) != 0 {
+		t.Errorf("expected the seen set not to be updated, got %v", slices.Collect(maps.Keys(seen)))
+	}
+	bounded := map[uint64]struct{}{1: {}, 2: {}, 3: {}}
+	_, _ = SplitLines([]byte("a\nb\n"), WithBoundedSeenFilter(bounded, 1))
+	if len(bounded) != 3 {
+		t.Errorf("expected the bounded seen set not to be updated, got %v", slices.Collect(maps.Keys(bounded)))
+	}
+	if h.Sum64() != fnv.New64a().Sum64() {
+		t.Error("expected the checksum not to be updated")
+	}
+	if passthrough.Len() != 0 {
+		t.Errorf("expected nothing passed through, got %q", passthrough.String())
+	}
+	// Sending panics if the channel was closed
+	ch <- "open"
+	if evicted != 0 {
+		t.Errorf("expected no evictions reported, got %d", evicted)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no files, got %d", len(entries))
+	}
+}
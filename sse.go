@@ -0,0 +1,98 @@
+package tail
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// sseNewlines normalizes the line terminators of server-sent events to "\n",
+// as a "\r" left in a data field would end it.
+var sseNewlines = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// sseStream is a subscriber queueing completed lines for ServeSSE.
+type sseStream struct {
+	mu     sync.Mutex
+	queue  []string
+	size   int
+	notify chan struct{}
+}
+
+// ServeSSE streams the lines as server-sent events to w: it sets the headers, sends the completed lines
+// currently maintained, then sends each new line as it is completed, flushing after each batch of events,
+// until ctx is done, e.g. the context of the request so that it stops when the client disconnects.
+// Each line is sent as an event with a "data" field per line of its text, split at "\r\n", "\r" and "\n".
+// If the client falls behind by more than maxLines lines, the oldest unsent lines are dropped.
+// It returns nil when ctx is done, or the error writing to w, e.g. http.ErrNotSupported if w cannot be flushed.
+func (tb *TailBuffer) ServeSSE(ctx context.Context, w http.ResponseWriter) error {
+	s := &sseStream{
+		notify: make(chan struct{}, 1),
+	}
+
+	tb.mu.Lock()
+	s.size = max(tb.maxLines, 1)
+	for _, l := range tb.lines {
+		s.queue = append(s.queue, l.text)
+	}
+	tb.subscribe(s)
+	tb.mu.Unlock()
+	defer tb.unsubscribe(s)
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+	if err := rc.Flush(); err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	for {
+		s.mu.Lock()
+		queue := s.queue
+		s.queue = nil
+		s.mu.Unlock()
+
+		if len(queue) > 0 {
+			sb.Reset()
+			for _, text := range queue {
+				for _, data := range strings.Split(sseNewlines.Replace(text), "\n") {
+					sb.WriteString("data: ")
+					sb.WriteString(data)
+					sb.WriteString("\n")
+				}
+				sb.WriteString("\n")
+			}
+			if _, err := w.Write([]byte(sb.String())); err != nil {
+				return err
+			}
+			if err := rc.Flush(); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.notify:
+		}
+	}
+}
+
+// push queues a completed line without blocking, dropping the oldest queued lines exceeding the size.
+func (s *sseStream) push(text string) {
+	s.mu.Lock()
+	s.queue = append(s.queue, text)
+	if len(s.queue) > s.size {
+		s.queue = s.queue[len(s.queue)-s.size:]
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,81 @@
+package tail
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTailBuffer_ServeSSE(t *testing.T) {
+	tw := New(2, WithContinuation(func(line string) bool { return strings.HasPrefix(line, " ") }))
+	if _, err := tw.Write([]byte("line1\nline2\nline3\n at\npen")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	served := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served <- tw.ServeSSE(r.Context(), w)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if expected, result := "text/event-stream", res.Header.Get("Content-Type"); result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+
+	r := bufio.NewReader(res.Body)
+	readEvent := func() string {
+		t.Helper()
+		var event string
+		for {
+			s, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			event += s
+			if s == "\n" {
+				return event
+			}
+		}
+	}
+
+	// The completed lines currently maintained are sent first
+	for _, expected := range []string{"data: line2\n\n", "data: line3\ndata:  at\n\n"} {
+		if result := readEvent(); result != expected {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	}
+	if _, err := tw.Write([]byte("ding\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected, result := "data: pending\n\n", readEvent(); result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+
+	// Carriage returns, which terminate fields of server-sent events, split the data
+	if _, err := tw.Write([]byte("progress 1\rprogress 2\r\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected, result := "data: progress 1\ndata: progress 2\n\n", readEvent(); result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+
+	// Disconnecting the client stops serving
+	cancel()
+	if err := <-served; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
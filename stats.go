@@ -0,0 +1,218 @@
+package tail
+
+import "slices"
+
+// Stats is a consistent snapshot of the counters of a TailBuffer.
+type Stats struct {
+	// TotalLines is the number of lines completed since the TailBuffer was created.
+	TotalLines int64
+	// TotalBytesWritten is the number of bytes written since the TailBuffer was created.
+	TotalBytesWritten int64
+	// Len is the number of maintained lines, including the pending line.
+	Len int
+	// RetainedBytes is the total length of the maintained lines, excluding line terminators.
+	RetainedBytes int
+	// Overflowed reports whether older lines have been evicted.
+	Overflowed bool
+}
+
+// Stats returns all counters captured at once.
+func (tb *TailBuffer) Stats() Stats {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	return tb.stats()
+}
+
+// stats returns the counters from the tracked totals, without taking a snapshot of the lines.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) stats() Stats {
+	n := len(tb.lines)
+	retained := tb.lineBytes - tb.termBytes
+	if tb.pendingSeparate() {
+		n++
+		retained += len(tb.pendingText())
+		// The oldest lines are trimmed to make room for the pending line, as snapshot does
+		if tb.sectionStart == nil && tb.spanBegin == nil && tb.maxLines > 0 && n > tb.maxLines {
+			for _, l := range tb.lines[:n-tb.maxLines] {
+				retained -= len(l.text)
+			}
+			n = tb.maxLines
+		}
+	} else if tb.buffer.Len() > 0 && !tb.pendingHidden() && tb.pendingContinues() {
+		retained += len("\n") + len(tb.pendingText())
+	}
+
+	return Stats{
+		TotalLines:        tb.totalLines,
+		TotalBytesWritten: tb.totalBytes,
+		Len:               n,
+		RetainedBytes:     retained,
+		Overflowed:        tb.overflowed,
+	}
+}
+
+// statsOf returns the counters with snapshot as the maintained lines.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) statsOf(snapshot []line) Stats {
+	retained := 0
+	for _, l := range snapshot {
+		retained += len(l.text)
+	}
+
+	return Stats{
+		TotalLines:        tb.totalLines,
+		TotalBytesWritten: tb.totalBytes,
+		Len:               len(snapshot),
+		RetainedBytes:     retained,
+		Overflowed:        tb.overflowed,
+	}
+}
+
+// TotalLines returns the number of lines completed since the TailBuffer was created.
+// It is not affected by Reset.
+func (tb *TailBuffer) TotalLines() int64 {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	return tb.totalLines
+}
+
+// TotalBytesWritten returns the number of bytes written since the TailBuffer was created.
+// It is not affected by Reset.
+func (tb *TailBuffer) TotalBytesWritten() int64 {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	return tb.totalBytes
+}
+
+// Len returns the number of maintained lines, including the pending line.
+func (tb *TailBuffer) Len() int {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	return tb.stats().Len
+}
+
+// RetainedBytes returns the total length of the maintained lines, excluding line terminators.
+func (tb *TailBuffer) RetainedBytes() int {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	return tb.stats().RetainedBytes
+}
+
+// Overflowed reports whether older lines have been evicted since the TailBuffer was created
+// or last reset.
+func (tb *TailBuffer) Overflowed() bool {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	return tb.overflowed
+}
+
+// ContiguousLines returns the maintained lines, including the pending line, and whether they are
+// all the lines written since the TailBuffer was created or last reset, with no line evicted before them.
+// Unlike Lines, it never includes the overflow marker or the first line caption.
+// Like Overflowed, the state is cleared by ResetOverflow.
+func (tb *TailBuffer) ContiguousLines() ([]string, bool) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	ts := tb.textSnapshot()
+	// The pending line may trim the oldest line without setting the overflow state
+	retained := len(tb.lines)
+	if tb.pendingSeparate() {
+		retained++
+	}
+	return ts.texts, !tb.overflowed && len(ts.texts) == retained
+}
+
+// LengthHistogram returns the number of completed lines by length, bucketed by powers of two.
+// Bucket 0 counts empty lines and bucket i counts lines of length in [2^(i-1), 2^i).
+// Trailing empty buckets are omitted. It returns nil if WithLengthHistogram is not set.
+func (tb *TailBuffer) LengthHistogram() []int {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if tb.lengthHistogram == nil {
+		return nil
+	}
+	n := len(tb.lengthHistogram)
+	for n > 0 && tb.lengthHistogram[n-1] == 0 {
+		n--
+	}
+	return slices.Clone(tb.lengthHistogram[:n])
+}
+
+// Snapshot is the maintained lines and the counters captured at once.
+type Snapshot struct {
+	Lines []string
+	Stats Stats
+}
+
+// Snapshot returns the maintained lines, without the overflow marker, and the counters captured at once,
+// e.g. to be passed to DiffString later.
+func (tb *TailBuffer) Snapshot() Snapshot {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	return tb.takeSnapshot()
+}
+
+// takeSnapshot returns the maintained lines and the counters as Snapshot does.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) takeSnapshot() Snapshot {
+	snapshot := tb.snapshot()
+	lines := make([]string, len(snapshot))
+	for i, l := range snapshot {
+		lines[i] = l.text
+	}
+	return Snapshot{
+		Lines: lines,
+		Stats: tb.statsOf(snapshot),
+	}
+}
+
+// FlushSnapshotReset completes the pending line, captures the maintained lines and the counters,
+// and then discards the lines as Reset does, all atomically so that no write slips in between,
+// e.g. for exporters shipping the lines at intervals. The pending line is included
+// even with WithExcludePending, and is counted in TotalLines.
+// If the TailBuffer is frozen, it returns the same as Snapshot and keeps the lines.
+func (tb *TailBuffer) FlushSnapshotReset() Snapshot {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if tb.frozen {
+		return tb.takeSnapshot()
+	}
+
+	snapshot := tb.completedLines()
+	if tb.buffer.Len() > 0 && tb.pendingContinues() {
+		snapshot[len(snapshot)-1].text += "\n" + tb.pendingText()
+		tb.totalLines++
+	} else if tb.buffer.Len() > 0 {
+		snapshot = append(snapshot, line{text: tb.pendingText(), term: tb.primaryDelimiter()})
+		tb.totalLines++
+		if tb.sectionStart == nil && tb.spanBegin == nil && tb.maxLines > 0 && len(snapshot) > tb.maxLines {
+			if tb.dropsNewest() {
+				snapshot = snapshot[:tb.maxLines]
+			} else {
+				snapshot = snapshot[len(snapshot)-tb.maxLines:]
+			}
+			tb.overflowed = true
+		}
+	}
+
+	lines := make([]string, len(snapshot))
+	for i, l := range snapshot {
+		lines[i] = l.text
+	}
+	s := Snapshot{
+		Lines: lines,
+		Stats: tb.statsOf(snapshot),
+	}
+	tb.reset()
+	return s
+}
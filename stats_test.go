@@ -0,0 +1,247 @@
+package tail
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTailBuffer_Stats(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		opts     []Option
+		writes   []string
+		expected Stats
+	}{
+		{
+			name:     "empty buffer",
+			limit:    3,
+			writes:   nil,
+			expected: Stats{},
+		},
+		{
+			name:   "not overflowed",
+			limit:  3,
+			writes: []string{"line1\n", "line2\nli"},
+			expected: Stats{
+				TotalLines:        2,
+				TotalBytesWritten: 14,
+				Len:               3,
+				RetainedBytes:     12,
+				Overflowed:        false,
+			},
+		},
+		{
+			name:   "overflowed",
+			limit:  2,
+			writes: []string{"line1\nline2\n", "line3\r\n"},
+			expected: Stats{
+				TotalLines:        3,
+				TotalBytesWritten: 19,
+				Len:               2,
+				RetainedBytes:     10,
+				Overflowed:        true,
+			},
+		},
+		{
+			name:   "pending line trims the oldest line",
+			limit:  2,
+			writes: []string{"line1\nline2\nli"},
+			expected: Stats{
+				TotalLines:        2,
+				TotalBytesWritten: 14,
+				Len:               2,
+				RetainedBytes:     7,
+				Overflowed:        false,
+			},
+		},
+		{
+			name:   "pending continuation line",
+			limit:  2,
+			opts:   []Option{WithContinuation(func(line string) bool { return strings.HasPrefix(line, " ") })},
+			writes: []string{"line1\n more"},
+			expected: Stats{
+				TotalLines:        1,
+				TotalBytesWritten: 11,
+				Len:               1,
+				RetainedBytes:     11,
+				Overflowed:        false,
+			},
+		},
+		{
+			name:   "pending line excluded",
+			limit:  2,
+			opts:   []Option{WithExcludePending()},
+			writes: []string{"line1\nli"},
+			expected: Stats{
+				TotalLines:        1,
+				TotalBytesWritten: 8,
+				Len:               1,
+				RetainedBytes:     5,
+				Overflowed:        false,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(tt.limit, tt.opts...)
+			for _, data := range tt.writes {
+				if _, err := tw.Write([]byte(data)); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			if result := tw.Stats(); result != tt.expected {
+				t.Errorf("Stats(): expected %+v, got %+v", tt.expected, result)
+			}
+			if result := tw.TotalLines(); result != tt.expected.TotalLines {
+				t.Errorf("TotalLines(): expected %d, got %d", tt.expected.TotalLines, result)
+			}
+			if result := tw.TotalBytesWritten(); result != tt.expected.TotalBytesWritten {
+				t.Errorf("TotalBytesWritten(): expected %d, got %d", tt.expected.TotalBytesWritten, result)
+			}
+			if result := tw.Len(); result != tt.expected.Len {
+				t.Errorf("Len(): expected %d, got %d", tt.expected.Len, result)
+			}
+			if result := tw.RetainedBytes(); result != tt.expected.RetainedBytes {
+				t.Errorf("RetainedBytes(): expected %d, got %d", tt.expected.RetainedBytes, result)
+			}
+			if result := tw.Overflowed(); result != tt.expected.Overflowed {
+				t.Errorf("Overflowed(): expected %v, got %v", tt.expected.Overflowed, result)
+			}
+			if result := tw.Snapshot().Stats; result != tt.expected {
+				t.Errorf("Snapshot().Stats: expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_Stats_Allocs(t *testing.T) {
+	tw := New(1000)
+	for range 1000 {
+		if _, err := tw.Write([]byte("line\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if allocs := testing.AllocsPerRun(10, func() { _ = tw.Stats() }); allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
+
+func TestTailBuffer_LengthHistogram(t *testing.T) {
+	tw := New(2, WithLengthHistogram())
+	input := "\na\nbb\nccc\ndddd\n" + strings.Repeat("e", 1000) + "\npending"
+	if _, err := tw.Write([]byte(input)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Buckets: 0, [1,2), [2,4), [4,8), ..., [512,1024)
+	expected := []int{1, 1, 2, 1, 0, 0, 0, 0, 0, 0, 1}
+	if result := tw.LengthHistogram(); !slices.Equal(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+
+	if result := New(2).LengthHistogram(); result != nil {
+		t.Errorf("expected nil, got %v", result)
+	}
+}
+
+func TestTailBuffer_FlushSnapshotReset(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		input    string
+		expected Snapshot
+	}{
+		{
+			name:  "pending line is flushed",
+			input: "line1\nline2\npen",
+			expected: Snapshot{
+				Lines: []string{"line1", "line2", "pen"},
+				Stats: Stats{TotalLines: 3, TotalBytesWritten: 15, Len: 3, RetainedBytes: 13},
+			},
+		},
+		{
+			name:  "flushed line evicts the oldest line",
+			input: "line1\nline2\nline3\npen",
+			expected: Snapshot{
+				Lines: []string{"line2", "line3", "pen"},
+				Stats: Stats{TotalLines: 4, TotalBytesWritten: 21, Len: 3, RetainedBytes: 13, Overflowed: true},
+			},
+		},
+		{
+			name:  "with WithExcludePending",
+			opts:  []Option{WithExcludePending()},
+			input: "line1\npen",
+			expected: Snapshot{
+				Lines: []string{"line1", "pen"},
+				Stats: Stats{TotalLines: 2, TotalBytesWritten: 9, Len: 2, RetainedBytes: 8},
+			},
+		},
+		{
+			name:  "empty",
+			input: "",
+			expected: Snapshot{
+				Lines: []string{},
+				Stats: Stats{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(3, tt.opts...)
+			if _, err := tw.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			s := tw.FlushSnapshotReset()
+			if !slices.Equal(s.Lines, tt.expected.Lines) || s.Lines == nil {
+				t.Errorf("expected %q, got %q", tt.expected.Lines, s.Lines)
+			}
+			if s.Stats != tt.expected.Stats {
+				t.Errorf("expected %+v, got %+v", tt.expected.Stats, s.Stats)
+			}
+
+			// The buffer starts fresh
+			if result := tw.Lines(); len(result) != 0 {
+				t.Errorf("expected no lines, got %q", result)
+			}
+			if tw.Overflowed() {
+				t.Error("expected not overflowed")
+			}
+		})
+	}
+}
+
+func TestTailBuffer_ContiguousLines(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		expected   []string
+		contiguous bool
+	}{
+		{"empty", "", []string{}, true},
+		{"within limit", "a\nb\n", []string{"a", "b"}, true},
+		{"with pending", "a\nb", []string{"a", "b"}, true},
+		{"evicted", "a\nb\nc\nd\n", []string{"b", "c", "d"}, false},
+		{"trimmed by pending", "a\nb\nc\nd", []string{"b", "c", "d"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(3, WithOverflowMarker("..."), WithKeepFirstLine(true))
+			if _, err := tw.Write([]byte(tt.data)); err != nil {
+				t.Fatal(err)
+			}
+			lines, contiguous := tw.ContiguousLines()
+			if !slices.Equal(lines, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, lines)
+			}
+			if contiguous != tt.contiguous {
+				t.Errorf("expected %v, got %v", tt.contiguous, contiguous)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package tail
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// subscriber receives lines as they are completed.
+type subscriber interface {
+	// push receives a completed line. It must not block.
+	push(text string)
+}
+
+// subscribe registers s to receive completed lines.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) subscribe(s subscriber) {
+	if tb.subscribers == nil {
+		tb.subscribers = map[subscriber]struct{}{}
+	}
+	tb.subscribers[s] = struct{}{}
+}
+
+// unsubscribe stops sending completed lines to s.
+func (tb *TailBuffer) unsubscribe(s subscriber) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	delete(tb.subscribers, s)
+}
+
+// notifySubscribers sends a completed line to all subscribers.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) notifySubscribers(text string) {
+	if len(tb.subscribers) == 0 {
+		return
+	}
+	for s := range tb.subscribers {
+		s.push(text)
+	}
+}
+
+// unsubscribeFlushTimeout is how long the batches left when unsubscribing are waited to be received.
+const unsubscribeFlushTimeout = time.Second
+
+// batcher is a subscriber that delivers completed lines in batches.
+type batcher struct {
+	mu       sync.Mutex
+	queue    []string
+	size     int
+	maxBatch int
+	ch       chan []string
+	full     chan struct{}
+	done     chan struct{}
+}
+
+// SubscribeBatched returns a channel receiving lines completed after the call in batches of up to maxBatch lines,
+// and a function to unsubscribe. A batch is delivered when maxBatch lines are collected or maxDelay has elapsed,
+// whichever comes first; a maxDelay of zero or less disables the latter.
+// Writes never block on a slow consumer: if more than max(maxLines, maxBatch) lines are waiting to be delivered,
+// the oldest of them are dropped.
+// Unsubscribing delivers the lines not delivered yet and then closes the channel. If the consumer does not receive
+// them within a second, e.g. because it has stopped receiving, they are dropped and the channel is closed anyway.
+func (tb *TailBuffer) SubscribeBatched(maxBatch int, maxDelay time.Duration) (<-chan []string, func()) {
+	maxBatch = max(maxBatch, 1)
+	b := &batcher{
+		maxBatch: maxBatch,
+		ch:       make(chan []string),
+		full:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+
+	tb.mu.Lock()
+	b.size = max(tb.maxLines, maxBatch)
+	tb.subscribe(b)
+	tb.mu.Unlock()
+
+	go b.run(maxDelay)
+
+	var once sync.Once
+	return b.ch, func() {
+		once.Do(func() {
+			tb.unsubscribe(b)
+			close(b.done)
+		})
+	}
+}
+
+// push queues a completed line without blocking, dropping the oldest queued lines exceeding the size.
+func (b *batcher) push(text string) {
+	b.mu.Lock()
+	b.queue = append(b.queue, text)
+	if len(b.queue) > b.size {
+		b.queue = b.queue[len(b.queue)-b.size:]
+	}
+	full := len(b.queue) >= b.maxBatch
+	b.mu.Unlock()
+
+	if full {
+		select {
+		case b.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run delivers batches until unsubscribed, and then the lines left as drain does.
+func (b *batcher) run(maxDelay time.Duration) {
+	defer close(b.ch)
+
+	var tick <-chan time.Time
+	if maxDelay > 0 {
+		ticker := time.NewTicker(maxDelay)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-b.done:
+			b.drain()
+			return
+		case <-b.full:
+			if !b.flush(true) {
+				b.drain()
+				return
+			}
+		case <-tick:
+			if !b.flush(false) {
+				b.drain()
+				return
+			}
+		}
+	}
+}
+
+// flush delivers the queued lines in batches of up to maxBatch lines.
+// If onlyFull is true, a last batch smaller than maxBatch is kept queued.
+// It returns false if unsubscribed before all the batches are delivered, keeping the batch being delivered queued.
+func (b *batcher) flush(onlyFull bool) bool {
+	for {
+		batch := b.next(onlyFull)
+		if batch == nil {
+			return true
+		}
+		select {
+		case b.ch <- batch:
+		case <-b.done:
+			b.mu.Lock()
+			b.queue = append(batch, b.queue...)
+			b.mu.Unlock()
+			return false
+		}
+	}
+}
+
+// drain delivers the lines left queued after unsubscribing, giving up after unsubscribeFlushTimeout
+// so that a consumer which has stopped receiving does not keep the batcher running.
+func (b *batcher) drain() {
+	timer := time.NewTimer(unsubscribeFlushTimeout)
+	defer timer.Stop()
+
+	for {
+		batch := b.next(false)
+		if batch == nil {
+			return
+		}
+		select {
+		case b.ch <- batch:
+		case <-timer.C:
+			return
+		}
+	}
+}
+
+// next removes the next batch of up to maxBatch lines from the queue and returns it,
+// or nil if the queue is empty, or if onlyFull is true and fewer than maxBatch lines are queued.
+func (b *batcher) next(onlyFull bool) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := min(len(b.queue), b.maxBatch)
+	if n == 0 || (onlyFull && n < b.maxBatch) {
+		return nil
+	}
+	batch := slices.Clone(b.queue[:n])
+	b.queue = b.queue[n:]
+	return batch
+}
@@ -0,0 +1,97 @@
+package tail
+
+import (
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTailBuffer_SubscribeBatched(t *testing.T) {
+	t.Run("batch size", func(t *testing.T) {
+		tw := New(10)
+		if _, err := tw.Write([]byte("before\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ch, unsubscribe := tw.SubscribeBatched(3, time.Hour)
+
+		if _, err := tw.Write([]byte("1\n2\n3\n4\n5\n6\n7\n8")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, expected := range [][]string{{"1", "2", "3"}, {"4", "5", "6"}} {
+			if batch := <-ch; !slices.Equal(batch, expected) {
+				t.Errorf("expected %q, got %q", expected, batch)
+			}
+		}
+
+		// Unsubscribing delivers the pending batch and closes the channel
+		unsubscribe()
+		if batch := <-ch; !slices.Equal(batch, []string{"7"}) {
+			t.Errorf("expected %q, got %q", []string{"7"}, batch)
+		}
+		if batch, ok := <-ch; ok {
+			t.Errorf("expected closed channel, got %q", batch)
+		}
+
+		// Lines written after unsubscribing are not delivered
+		if _, err := tw.Write([]byte("\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		unsubscribe()
+	})
+
+	t.Run("unsubscribe without receiving", func(t *testing.T) {
+		tw := New(10)
+		before := runtime.NumGoroutine()
+		_, unsubscribe := tw.SubscribeBatched(1, time.Hour)
+		if _, err := tw.Write([]byte("1\n2\n3\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// The batcher is blocked delivering a batch nobody receives
+		time.Sleep(10 * time.Millisecond)
+		unsubscribe()
+		deadline := time.Now().Add(2 * unsubscribeFlushTimeout)
+		for runtime.NumGoroutine() > before {
+			if time.Now().After(deadline) {
+				t.Fatal("timed out waiting for the batcher to stop")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	t.Run("max delay", func(t *testing.T) {
+		tw := New(10)
+		ch, unsubscribe := tw.SubscribeBatched(100, 10*time.Millisecond)
+		defer unsubscribe()
+
+		if _, err := tw.Write([]byte("1\n2\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		select {
+		case batch := <-ch:
+			if expected := []string{"1", "2"}; !slices.Equal(batch, expected) {
+				t.Errorf("expected %q, got %q", expected, batch)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for a batch")
+		}
+	})
+	t.Run("max lines of 0", func(t *testing.T) {
+		tw := New(0)
+		ch, unsubscribe := tw.SubscribeBatched(2, time.Hour)
+		defer unsubscribe()
+
+		if _, err := tw.Write([]byte("a\nb\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		select {
+		case batch := <-ch:
+			if expected := []string{"a", "b"}; !slices.Equal(batch, expected) {
+				t.Errorf("expected %q, got %q", expected, batch)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for a batch")
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package tail
+
+// NamedTailConfig configures a named tail set with WithNamedTails.
+type NamedTailConfig struct {
+	// Pred reports whether a completed line is maintained by the named tail.
+	Pred func(line string) bool
+	// MaxLines is the number of lines the named tail maintains.
+	MaxLines int
+}
+
+// subTail is a secondary window maintaining the last lines matching a predicate,
+// set with WithErrorTail or WithNamedTails.
+type subTail struct {
+	pred     func(line string) bool
+	maxLines int
+	lines    []string
+}
+
+// ErrorLines returns the completed lines maintained by the error tail set with WithErrorTail.
+// It returns an empty slice if WithErrorTail is not set.
+func (tb *TailBuffer) ErrorLines() []string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if tb.errorTail == nil {
+		return []string{}
+	}
+	return append([]string{}, tb.errorTail.lines...)
+}
+
+// NamedLines returns the completed lines maintained by the named tail set with WithNamedTails.
+// It returns an empty slice if there is no such tail.
+func (tb *TailBuffer) NamedLines(name string) []string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	st, ok := tb.namedTails[name]
+	if !ok {
+		return []string{}
+	}
+	return append([]string{}, st.lines...)
+}
+
+// pushSubTails feeds a completed line to the error tail and the named tails.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) pushSubTails(text string) {
+	if tb.errorTail != nil {
+		tb.errorTail.push(text)
+	}
+	for _, name := range tb.namedTailOrder {
+		tb.namedTails[name].push(text)
+	}
+}
+
+// push maintains text if it matches the predicate, evicting the oldest line exceeding maxLines.
+func (st *subTail) push(text string) {
+	if st.maxLines <= 0 || !st.pred(text) {
+		return
+	}
+	if len(st.lines) == st.maxLines {
+		copy(st.lines, st.lines[1:])
+		st.lines = st.lines[:len(st.lines)-1]
+	}
+	st.lines = append(st.lines, text)
+}
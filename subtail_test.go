@@ -0,0 +1,116 @@
+package tail
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTailBuffer_WithErrorTail(t *testing.T) {
+	isError := func(line string) bool { return strings.Contains(line, "ERROR") }
+
+	tests := []struct {
+		name          string
+		limit         int
+		errorLimit    int
+		input         string
+		expected      []string
+		expectedError []string
+	}{
+		{
+			name:          "independent windows",
+			limit:         2,
+			errorLimit:    2,
+			input:         "ERROR a\ninfo\nERROR b\ninfo\ninfo\nERROR c\n",
+			expected:      []string{"info", "ERROR c"},
+			expectedError: []string{"ERROR b", "ERROR c"},
+		},
+		{
+			name:          "error tail keeps lines evicted from the main tail",
+			limit:         1,
+			errorLimit:    3,
+			input:         "ERROR a\ninfo\ninfo\n",
+			expected:      []string{"info"},
+			expectedError: []string{"ERROR a"},
+		},
+		{
+			name:          "pending line is not matched",
+			limit:         3,
+			errorLimit:    3,
+			input:         "info\nERROR a",
+			expected:      []string{"info", "ERROR a"},
+			expectedError: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(tt.limit, WithErrorTail(isError, tt.errorLimit))
+			if _, err := tw.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result := tw.Lines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+			if result := tw.ErrorLines(); !slices.Equal(result, tt.expectedError) {
+				t.Errorf("expected %q, got %q", tt.expectedError, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_ErrorLines_WithoutErrorTail(t *testing.T) {
+	tw := New(3)
+	if _, err := tw.Write([]byte("ERROR a\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result := tw.ErrorLines(); result == nil || len(result) != 0 {
+		t.Errorf("expected empty slice, got %q", result)
+	}
+}
+
+func TestTailBuffer_WithNamedTails(t *testing.T) {
+	tw := New(2, WithNamedTails(map[string]NamedTailConfig{
+		"errors": {
+			Pred:     func(line string) bool { return strings.HasPrefix(line, "ERROR") },
+			MaxLines: 2,
+		},
+		"warnings": {
+			Pred:     func(line string) bool { return strings.HasPrefix(line, "WARN") },
+			MaxLines: 1,
+		},
+		"problems": {
+			Pred:     func(line string) bool { return !strings.HasPrefix(line, "INFO") },
+			MaxLines: 3,
+		},
+	}))
+	if _, err := tw.Write([]byte("ERROR a\nWARN a\nINFO a\nERROR b\nWARN b\nERROR c\nINFO b\nERROR d")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		expected []string
+	}{
+		{"errors", []string{"ERROR b", "ERROR c"}},
+		{"warnings", []string{"WARN b"}},
+		{"problems", []string{"ERROR b", "WARN b", "ERROR c"}},
+		{"unknown", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tw.NamedLines(tt.name); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+
+	if expected, result := []string{"INFO b", "ERROR d"}, tw.Lines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+	tw.Reset()
+	if result := tw.NamedLines("errors"); result == nil || len(result) != 0 {
+		t.Errorf("expected empty slice, got %q", result)
+	}
+}
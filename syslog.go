@@ -0,0 +1,31 @@
+//go:build !windows && !plan9
+
+package tail
+
+import "log/syslog"
+
+// WriteToSyslog writes each maintained line, including the pending line, to w as a separate message
+// with the severity of pri, taken from a snapshot. The facility of pri is ignored, as it is set when w is created.
+// It stops at the first error. On platforms without log/syslog, use EachLine instead.
+func (tb *TailBuffer) WriteToSyslog(w *syslog.Writer, pri syslog.Priority) error {
+	var write func(m string) error
+	switch pri & 0x07 {
+	case syslog.LOG_EMERG:
+		write = w.Emerg
+	case syslog.LOG_ALERT:
+		write = w.Alert
+	case syslog.LOG_CRIT:
+		write = w.Crit
+	case syslog.LOG_ERR:
+		write = w.Err
+	case syslog.LOG_WARNING:
+		write = w.Warning
+	case syslog.LOG_NOTICE:
+		write = w.Notice
+	case syslog.LOG_INFO:
+		write = w.Info
+	default:
+		write = w.Debug
+	}
+	return tb.EachLine(write)
+}
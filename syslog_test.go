@@ -0,0 +1,51 @@
+//go:build !windows && !plan9
+
+package tail
+
+import (
+	"log/syslog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTailBuffer_WriteToSyslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer conn.Close()
+
+	w, err := syslog.Dial("udp", conn.LocalAddr().String(), syslog.LOG_LOCAL0|syslog.LOG_INFO, "tail")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Close()
+
+	tw := New(2)
+	if _, err := tw.Write([]byte("line1\nline2\npen")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := tw.WriteToSyslog(w, syslog.LOG_WARNING); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// <132> is the local0 facility with the warning severity
+	buf := make([]byte, 1024)
+	for _, expected := range []string{"line2", "pen"} {
+		if err := conn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+			t.Fatal(err)
+		}
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		msg := string(buf[:n])
+		if !strings.HasPrefix(msg, "<132>") || !strings.HasSuffix(msg, "tail["+strconv.Itoa(os.Getpid())+"]: "+expected+"\n") {
+			t.Errorf("unexpected message %q for %q", msg, expected)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package tail
+
+// TaggedLine is a maintained line with the tag it was written with.
+type TaggedLine struct {
+	Tag  string
+	Line string
+}
+
+// TaggedBuffer is a TailBuffer that maintains a tag, such as the source, with each line.
+type TaggedBuffer struct {
+	*TailBuffer
+}
+
+// NewTagged creates a new TaggedBuffer with the specified maximum number of lines.
+func NewTagged(maxLines int, opts ...Option) *TaggedBuffer {
+	return &TaggedBuffer{
+		TailBuffer: New(maxLines, opts...),
+	}
+}
+
+// WriteTagged writes data like Write, tagging the lines completed by it with tag.
+// Lines written with Write have an empty tag.
+// The pending line is shared by all tags, so each write should consist of whole lines
+// when multiplexing several sources.
+func (tb *TaggedBuffer) WriteTagged(p []byte, tag string) (n int, err error) {
+	n, _, err = tb.write(p, tag)
+	return n, err
+}
+
+// TaggedLines returns the maintained lines with their tags.
+// The pending line has the tag of the last write.
+func (tb *TaggedBuffer) TaggedLines() []TaggedLine {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	snapshot := tb.snapshot()
+	result := make([]TaggedLine, len(snapshot))
+	for i, l := range snapshot {
+		result[i] = TaggedLine{Tag: l.tag, Line: l.text}
+	}
+	return result
+}
@@ -0,0 +1,42 @@
+package tail
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTaggedBuffer_TaggedLines(t *testing.T) {
+	tb := NewTagged(3)
+	writes := []struct {
+		tag  string
+		data string
+	}{
+		{"app", "line1\n"},
+		{"db", "line2\nline3\n"},
+		{"", "line4\n"},
+		{"app", "line5"},
+	}
+	for _, w := range writes {
+		var err error
+		if w.tag == "" {
+			_, err = tb.Write([]byte(w.data))
+		} else {
+			_, err = tb.WriteTagged([]byte(w.data), w.tag)
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expected := []TaggedLine{
+		{Tag: "db", Line: "line3"},
+		{Tag: "", Line: "line4"},
+		{Tag: "app", Line: "line5"},
+	}
+	if result := tb.TaggedLines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+	if expected, result := []string{"line3", "line4", "line5"}, tb.Lines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
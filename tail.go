@@ -4,88 +4,922 @@ package tail
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/fnv"
 	"io"
+	"math/bits"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
 )
 
+const bom = "\uFEFF"
+
+// initialLinesCap is the number of lines preallocated by New. More space is allocated on demand up to maxLines lines,
+// so that a buffer with a large maxLines is cheap until it is filled.
+const initialLinesCap = 64
+
+// ErrInvalidUTF8 is returned by Write when a completed line contains invalid UTF-8 with WithRequireUTF8.
+var ErrInvalidUTF8 = errors.New("tail: invalid UTF-8 in line")
+
+// ErrClosed is returned by Write when the TailBuffer has been closed.
+var ErrClosed = errors.New("tail: write to closed buffer")
+
+// ErrFrozen is returned by Write when the TailBuffer has been frozen by Freeze.
+var ErrFrozen = errors.New("tail: write to frozen buffer")
+
+// TailWriter is the interface of a writer maintaining lines of written data, implemented by *TailBuffer,
+// so that code built on the package can depend on it and substitute fakes in tests.
+type TailWriter interface {
+	io.Writer
+	io.WriterTo
+	// Lines returns the maintained lines.
+	Lines() []string
+	// String returns the maintained lines joined by the delimiter.
+	String() string
+}
+
+var _ TailWriter = (*TailBuffer)(nil)
+
 // TailBuffer implements io.Writer and maintains the last N lines
 // of written data.
+// When no lines are maintained, accessors returning slices return non-nil empty slices.
 type TailBuffer struct {
 	mu       sync.Mutex
 	maxLines int
-	lines    []string
+	lines    []storedLine
 	buffer   bytes.Buffer
+	// store is the backing array of lines.
+	store []storedLine
+	// meta is the optional metadata of lines.
+	meta lineMeta
+	// lineBytes is the total length of the texts and terminators of lines.
+	lineBytes int
+	// termBytes is the total length of the terminators of lines.
+	termBytes int
+	// runes is the total number of runes of the texts of lines, tracked with WithMaxRunes.
+	runes int
+
+	delimiters      []byte
+	delimiterToken  string
+	continuation    func(line string) bool
+	sectionStart    func(line string) bool
+	spanBegin       func(line string) bool
+	spanEnd         func(line string) bool
+	inSpan          bool
+	resetOnMarker   func(line string) bool
+	keepMarker      bool
+	clearOnFormFeed bool
+	overflowMarker  string
+	timestamps      bool
+	timestampPrefix string
+	requireUTF8     bool
+	excludePending  bool
+	seen            *seenFilter
+	overflowed      bool
+	totalLines      int64
+	totalBytes      int64
+	subscribers     map[subscriber]struct{}
+	arena           *arena
+	collapseRuns    int
+	pendingTag      string
+	scopeKey        func() string
+	squeezeBlank    bool
+	maxKeys         int
+	drainCh         chan<- string
+	drainTimeout    time.Duration
+	closed          bool
+	frozen          bool
+	json            *jsonSplitter
+	frames          *frameSplitter
+	clock           func() time.Time
+	evictedLines    int
+	errorTail       *subTail
+	namedTails      map[string]*subTail
+	namedTailOrder  []string
+	trailingNewline bool
+	sequenceIDs     bool
+	writes          uint64
+	memoryCap       int
+	changesOnly     bool
+	lastText        string
+	hasLastText     bool
+	maxRunes        int
+	passthrough     io.Writer
+	sampler         *sampler
+	// pendingFlush is the state of WithPendingFlushInterval.
+	pendingFlush    *pendingFlush
+	writeTracker    *writeTracker
+	interner        *interner
+	latency         *latencyReservoir
+	evictions       *evictionNotifier
+	keepFirstLine   bool
+	prependFirst    bool
+	region          *region
+	redaction       []RedactionRule
+	shrinkPolicy    ShrinkPolicy
+	firstLine       string
+	hasFirstLine    bool
+	lastBlank       bool
+	pendingScope    string
+	checksum        hash.Hash
+	lengthHistogram []int
+	oneByte         [1]byte
+	offset          int64
+
+	decoder    *encoding.Decoder
+	undecoded  []byte
+	bomChecked bool
+}
+
+// line is a completed line with its original terminator, and its metadata.
+// The pending line has an empty terminator.
+type line struct {
+	text string
+	term string
+	// sectionStart reports whether the line starts a section.
+	sectionStart bool
+	// spanBegin reports whether the line begins a span.
+	spanBegin bool
+	// first reports whether the line is the first line kept with WithKeepFirstLine.
+	first bool
+	tag   string
+	// scope is the scope key of the write that completed the line, set with WithScopeKey.
+	scope string
+	// offset is the position in the written stream where the line begins.
+	offset int64
+	// seq is the sequence ID of the line, recorded with WithSequenceIDs.
+	seq uint64
+	// skipped is the number of bytes discarded before the line, such as whitespace between JSON values.
+	skipped int
+	// time is when the line was completed, recorded with WithTimestamps.
+	time time.Time
+}
+
+// storedLine is a maintained line. Its optional metadata is held in lineMeta instead,
+// so that the maintained lines stay small when no option recording metadata is set.
+type storedLine struct {
+	text   string
+	term   string
+	offset int64
+	// sectionStart reports whether the line starts a section.
+	sectionStart bool
+	// spanBegin reports whether the line begins a span.
+	spanBegin bool
+	// first reports whether the line is the first line kept with WithKeepFirstLine.
+	first bool
+}
+
+// newLine creates a line from s, which was terminated by term.
+// When term is "\n", trailing "\r"s are treated as part of a "\r\n" terminator, so that no text ends with "\r",
+// which would be read back as a part of the terminator once the text is followed by "\n" again, e.g. by String.
+func newLine(s string, term string) line {
+	if term == "\n" && strings.HasSuffix(s, "\r") {
+		text := strings.TrimRight(s, "\r")
+		if len(s)-len(text) == 1 {
+			return line{text: text, term: "\r\n"}
+		}
+		return line{text: text, term: s[len(text):] + term}
+	}
+	return line{text: s, term: term}
 }
 
 // New creates a new TailBuffer with the specified maximum number of lines.
-func New(maxLines int) *TailBuffer {
-	return &TailBuffer{
-		maxLines: maxLines,
-		lines:    make([]string, 0, maxLines),
+// A negative maxLines is treated as 0.
+func New(maxLines int, opts ...Option) *TailBuffer {
+	maxLines = max(maxLines, 0)
+	tb := &TailBuffer{
+		maxLines:   maxLines,
+		lines:      make([]storedLine, 0, min(maxLines, initialLinesCap)),
+		delimiters: []byte{'\n'},
+		clock:      time.Now,
+	}
+	tb.store = tb.lines
+	for _, opt := range opts {
+		opt(tb)
 	}
+	return tb
+}
+
+// FromLines creates a new TailBuffer maintaining the last maxLines of lines as completed lines,
+// with no pending line. The lines are maintained as is, without being split or processed by options.
+func FromLines(maxLines int, lines []string, opts ...Option) *TailBuffer {
+	tb := New(maxLines, opts...)
+	if maxLines <= 0 {
+		return tb
+	}
+	term := tb.primaryDelimiter()
+	for _, text := range lines[max(len(lines)-maxLines, 0):] {
+		tb.pushLine(&line{text: text, term: term})
+	}
+	return tb
 }
 
 // Write implements the io.Writer interface.
 // It writes data and maintains the last N lines.
 func (tb *TailBuffer) Write(p []byte) (n int, err error) {
+	n, _, err = tb.write(p, "")
+	return n, err
+}
+
+// WriteCounted writes data like Write, and also returns the number of maintained lines evicted by the write
+// due to maxLines, including lines completed by the write itself that do not fit.
+func (tb *TailBuffer) WriteCounted(p []byte) (n, evicted int, err error) {
+	return tb.write(p, "")
+}
+
+// write writes data, tagging the lines completed by it with tag.
+// It returns the number of lines evicted by the write in addition to the number of bytes written.
+func (tb *TailBuffer) write(p []byte, tag string) (n, evicted int, err error) {
+	pc := tb.callerPC(2)
+	start := tb.startLatency()
+	scope := tb.scope()
+	if tb.writeTracker != nil {
+		defer tb.warnWriters()
+	}
+	defer tb.deliverEvictions()
 	tb.mu.Lock()
 	defer tb.mu.Unlock()
+	defer tb.recordLatency(start)
+
+	if tb.closed {
+		return 0, 0, ErrClosed
+	}
+	if tb.frozen {
+		return 0, 0, ErrFrozen
+	}
+	tb.writes++
+	tb.trackWrite(pc)
+
+	// Forward the data as is before waiting for lines to complete
+	var forwardErr error
+	if tb.passthrough != nil && len(p) > 0 {
+		var written int
+		written, forwardErr = tb.passthrough.Write(p)
+		if forwardErr == nil && written < len(p) {
+			forwardErr = io.ErrShortWrite
+		}
+		// Only the data forwarded is maintained
+		p = p[:min(max(written, 0), len(p))]
+	}
 
 	n = len(p)
+	if n == 0 {
+		return 0, 0, forwardErr
+	}
 
-	// Add to buffer
-	tb.buffer.Write(p)
+	if tb.checksum != nil {
+		_, _ = tb.checksum.Write(p)
+	}
 
-	// Split buffer content into lines
-	content := tb.buffer.String()
-	lines := strings.Split(content, "\n")
+	// Transcode to UTF-8 before splitting lines
+	if tb.decoder != nil {
+		p, err = tb.decode(p)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
 
-	// If the last element is not empty, it's not a complete line yet
-	if len(lines) > 0 && lines[len(lines)-1] != "" {
-		// Keep the last incomplete line in the buffer
-		tb.buffer.Reset()
-		tb.buffer.WriteString(lines[len(lines)-1])
-		lines = lines[:len(lines)-1]
+	var lines []line
+	if tb.json != nil {
+		tb.buffer.Write(p)
+		lines = tb.splitJSON()
+	} else if tb.frames != nil {
+		tb.buffer.Write(p)
+		lines = tb.splitFrames()
+	} else if tb.isSingleLine(p) {
+		// Fast path: p is exactly one complete line and nothing is pending
+		var single [1]line
+		single[0] = newLine(lineText(tb, p[:len(p)-1]), string(p[len(p)-1:]))
+		lines = single[:]
 	} else {
-		// Clear the buffer
+		// Add to buffer
+		start := tb.buffer.Len()
+		tb.buffer.Write(p)
+
+		if tb.clearOnFormFeed {
+			if i := bytes.LastIndexByte(tb.buffer.Bytes()[start:], '\f'); i >= 0 {
+				// Discard everything before the last form feed
+				tb.clearLines()
+				tb.offset += int64(start + i + 1)
+				tb.buffer.Next(start + i + 1)
+				start = 0
+			}
+		}
+
+		// Only the written data needs to be scanned, in addition to the end of the pending line
+		// that may hold the beginning of a delimiter token split across writes
+		scan := max(start-max(len(tb.delimiterToken)-1, 0), 0)
+		if i, _ := indexDelimiter(tb, tb.buffer.Bytes()[scan:]); i >= 0 {
+			// Split buffer content into lines
+			content := tb.buffer.String()
+			for {
+				i, size := indexDelimiter(tb, content[scan:])
+				if i < 0 {
+					break
+				}
+				i += scan
+				lines = append(lines, newLine(lineText(tb, content[:i]), content[i:i+size]))
+				content = content[i+size:]
+				scan = 0
+			}
+
+			// Keep the last incomplete line in the buffer
+			tb.buffer.Reset()
+			tb.buffer.WriteString(content)
+		}
+	}
+
+	tb.totalBytes += int64(n)
+	tb.pendingTag = tag
+	tb.pendingScope = scope
+
+	before := tb.evictedLines
+	err = tb.complete(lines, tag)
+	tb.schedulePendingFlush()
+	if forwardErr != nil {
+		err = forwardErr
+	}
+	return n, tb.evictedLines - before, err
+}
+
+// WriteByte implements the io.ByteWriter interface.
+// It appends c to the pending line without allocating, and completes the line if c is a delimiter.
+func (tb *TailBuffer) WriteByte(c byte) (err error) {
+	pc := tb.callerPC(1)
+	start := tb.startLatency()
+	scope := tb.scope()
+	if tb.writeTracker != nil {
+		defer tb.warnWriters()
+	}
+	defer tb.deliverEvictions()
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	defer tb.recordLatency(start)
+
+	if tb.closed {
+		return ErrClosed
+	}
+	if tb.frozen {
+		return ErrFrozen
+	}
+	tb.writes++
+	tb.trackWrite(pc)
+	tb.oneByte[0] = c
+	p := tb.oneByte[:]
+	if tb.passthrough != nil {
+		written, err := tb.passthrough.Write(p)
+		if err == nil && written < len(p) {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if tb.checksum != nil {
+		_, _ = tb.checksum.Write(p)
+	}
+
+	// Transcode to UTF-8 before splitting lines
+	if tb.decoder != nil {
+		p, err = tb.decode(p)
+		if err != nil {
+			return err
+		}
+	}
+
+	tb.totalBytes++
+	tb.pendingTag = ""
+	tb.pendingScope = scope
+
+	for _, c := range p {
+		if tb.frames != nil {
+			tb.buffer.WriteByte(c)
+			if cerr := tb.complete(tb.splitFrames(), ""); cerr != nil {
+				err = cerr
+			}
+			continue
+		}
+		if c == '\f' && tb.clearOnFormFeed {
+			tb.clearLines()
+			tb.offset += int64(tb.buffer.Len() + 1)
+			tb.buffer.Reset()
+			continue
+		}
+		tb.buffer.WriteByte(c)
+		if tb.json != nil {
+			if cerr := tb.complete(tb.splitJSON(), ""); cerr != nil {
+				err = cerr
+			}
+			continue
+		}
+		pending := tb.buffer.Bytes()
+		i, size := indexDelimiter(tb, pending[max(len(pending)-max(len(tb.delimiterToken), 1), 0):])
+		if i < 0 {
+			tb.enforceMemoryCap()
+			continue
+		}
+		end := len(pending) - size
+		var single [1]line
+		single[0] = newLine(lineText(tb, pending[:end]), string(pending[end:]))
 		tb.buffer.Reset()
-		// Remove the last empty element
-		if len(lines) > 0 && lines[len(lines)-1] == "" {
-			lines = lines[:len(lines)-1]
+		if cerr := tb.complete(single[:], ""); cerr != nil {
+			err = cerr
+		}
+	}
+	tb.schedulePendingFlush()
+	return err
+}
+
+// complete processes the lines completed by a write, tagging them with tag, and maintains them.
+func (tb *TailBuffer) complete(lines []line, tag string) (err error) {
+	if tb.sequenceIDs {
+		for i := range lines {
+			lines[i].seq = uint64(tb.totalLines) + uint64(i) + 1
+		}
+	}
+	tb.totalLines += int64(len(lines))
+	for i := range lines {
+		tb.offset += int64(lines[i].skipped)
+		lines[i].offset = tb.offset
+		tb.offset += int64(len(lines[i].text) + len(lines[i].term))
+	}
+	if tb.lengthHistogram != nil {
+		for _, l := range lines {
+			tb.lengthHistogram[bits.Len(uint(len(l.text)))]++
+		}
+	}
+
+	// Mask sensitive data before the lines are processed further
+	if len(tb.redaction) > 0 {
+		for i := range lines {
+			lines[i].text = storeText(tb, tb.redact(lines[i].text))
+		}
+	}
+
+	// Drop lines outside the region of interest
+	lines = tb.filterRegion(lines)
+
+	// Drop lines identical to the previous line
+	if tb.changesOnly {
+		lines = slices.DeleteFunc(lines, func(l line) bool {
+			unchanged := tb.hasLastText && tb.lastText == l.text
+			tb.lastText = l.text
+			tb.hasLastText = true
+			return unchanged
+		})
+	}
+
+	// Drop lines containing invalid UTF-8
+	if tb.requireUTF8 {
+		lines = slices.DeleteFunc(lines, func(l line) bool {
+			if utf8.ValidString(l.text) {
+				return false
+			}
+			err = ErrInvalidUTF8
+			return true
+		})
+	}
+
+	// Skip lines that have already been seen
+	if tb.seen != nil {
+		lines = slices.DeleteFunc(lines, func(l line) bool {
+			return tb.seen.seenBefore(l.text)
+		})
+	}
+
+	// Collapse consecutive blank lines into one
+	if tb.squeezeBlank {
+		lines = slices.DeleteFunc(lines, func(l line) bool {
+			blank := strings.TrimSpace(l.text) == ""
+			squeezed := blank && tb.lastBlank
+			tb.lastBlank = blank
+			return squeezed
+		})
+	}
+
+	// Keep a sample of the lines that passed the filters
+	if tb.sampler != nil {
+		lines = slices.DeleteFunc(lines, func(l line) bool {
+			return !tb.sampler.keep()
+		})
+	}
+
+	for i := range lines {
+		lines[i].tag = tag
+		lines[i].scope = tb.pendingScope
+	}
+
+	// Collapse long runs of identical characters
+	if tb.collapseRuns > 0 {
+		for i := range lines {
+			lines[i].text = collapseRuns(lines[i].text, tb.collapseRuns)
 		}
 	}
 
+	// Truncate lines too long to fit in the memory cap
+	if tb.memoryCap > 0 {
+		for i := range lines {
+			tb.truncateToMemoryCap(&lines[i])
+		}
+	}
+
+	for _, l := range lines {
+		tb.pushSubTails(l.text)
+	}
+
+	// Keep the first line separately
+	if tb.keepFirstLine && !tb.hasFirstLine && len(lines) > 0 {
+		lines[0].first = true
+		tb.firstLine = lines[0].text
+		tb.hasFirstLine = true
+	}
+
 	// Don't keep any lines if maxLines is 0
 	if tb.maxLines == 0 {
-		tb.lines = []string{}
+		tb.lines = []storedLine{}
+		// Subscribers still receive the lines completed
+		for i := range lines {
+			tb.notifySubscribers(lines[i].text)
+		}
 	} else {
 		// Add new lines
-		tb.lines = append(tb.lines, lines...)
+		for i := range lines {
+			tb.appendLine(&lines[i])
+			if tb.maxRunes > 0 {
+				// Apply the limits line by line, as truncating a line depends on the lines following it,
+				// so that the lines maintained do not depend on how the data is split into writes
+				tb.evict()
+				tb.evictRunes()
+			}
+		}
 
-		// Remove old lines if exceeding maxLines
-		if len(tb.lines) > tb.maxLines {
-			tb.lines = tb.lines[len(tb.lines)-tb.maxLines:]
+		tb.evict()
+	}
+	tb.enforceMemoryCap()
+
+	return err
+}
+
+// collapseRuns collapses each run of the same character in s longer than maxRun
+// into maxRun copies followed by a marker "[+N]", where N is the number of omitted characters.
+func collapseRuns(s string, maxRun int) string {
+	var sb strings.Builder
+	collapsed := false
+	for i := 0; i < len(s); {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		run := s[i : i+size]
+		count := 1
+		for strings.HasPrefix(s[i+count*size:], run) {
+			count++
+		}
+		if count > maxRun {
+			if !collapsed {
+				collapsed = true
+				sb.Grow(len(s))
+				sb.WriteString(s[:i])
+			}
+			sb.WriteString(strings.Repeat(run, maxRun))
+			fmt.Fprintf(&sb, "[+%d]", count-maxRun)
+		} else if collapsed {
+			sb.WriteString(s[i : i+count*size])
 		}
+		i += count * size
+	}
+	if !collapsed {
+		return s
 	}
+	return sb.String()
+}
 
-	return n, nil
+// evict removes old lines exceeding maxLines.
+func (tb *TailBuffer) evict() {
+	if tb.dropsNewest() {
+		tb.dropNewest(tb.maxLines)
+		return
+	}
+
+	start := len(tb.lines) - tb.maxLines
+	if tb.sectionStart != nil {
+		start = tb.sectionEvictionIndex()
+	}
+	if tb.spanBegin != nil {
+		start = tb.spanEvictionIndex()
+	}
+	tb.dropOldest(start)
 }
 
-// Lines returns the maintained lines as a slice.
-func (tb *TailBuffer) Lines() []string {
+// evictRunes evicts the oldest lines so that the completed lines have at most maxRunes runes in total,
+// truncating the newest line at a rune boundary if it has more runes by itself.
+func (tb *TailBuffer) evictRunes() {
+	if tb.maxRunes <= 0 || tb.runes <= tb.maxRunes {
+		return
+	}
+	total := tb.runes
+	start := 0
+	for total > tb.maxRunes && start < len(tb.lines)-1 {
+		total -= utf8.RuneCountInString(tb.lines[start].text)
+		start++
+	}
+	if start > 0 {
+		tb.recordEvictions(0, start, EvictedByMaxRunes)
+		tb.forgetLines(tb.lines[:start])
+		clear(tb.lines[:start])
+		tb.lines = tb.lines[start:]
+		tb.meta.drop(start)
+		tb.overflowed = true
+		tb.evictedLines += start
+	}
+	if tb.runes > tb.maxRunes {
+		// The newest line does not fit by itself
+		last := &tb.lines[len(tb.lines)-1]
+		tb.lineBytes -= len(last.text)
+		last.text = truncateRunes(last.text, tb.maxRunes)
+		tb.lineBytes += len(last.text)
+		tb.runes = tb.maxRunes
+	}
+}
+
+// truncateRunes returns the first n runes of s.
+func truncateRunes(s string, n int) string {
+	for i := range s {
+		if n == 0 {
+			return strings.Clone(s[:i])
+		}
+		n--
+	}
+	return s
+}
+
+// isSingleLine reports whether p is a single line terminated by the delimiter with no pending data.
+func (tb *TailBuffer) isSingleLine(p []byte) bool {
+	if len(p) == 0 || tb.buffer.Len() > 0 || len(tb.delimiters) != 1 || tb.delimiterToken != "" || tb.clearOnFormFeed {
+		return false
+	}
+	delim := tb.delimiters[0]
+	return p[len(p)-1] == delim && bytes.IndexByte(p[:len(p)-1], delim) < 0
+}
+
+// indexDelimiter returns the index and the size of the first delimiter in s, or -1 and 0.
+func indexDelimiter[S string | []byte](tb *TailBuffer, s S) (int, int) {
+	if tb.delimiterToken != "" {
+		switch s := any(s).(type) {
+		case string:
+			return strings.Index(s, tb.delimiterToken), len(tb.delimiterToken)
+		case []byte:
+			return bytes.Index(s, []byte(tb.delimiterToken)), len(tb.delimiterToken)
+		}
+	}
+	if len(tb.delimiters) == 1 {
+		switch s := any(s).(type) {
+		case string:
+			return strings.IndexByte(s, tb.delimiters[0]), 1
+		case []byte:
+			return bytes.IndexByte(s, tb.delimiters[0]), 1
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		if bytes.IndexByte(tb.delimiters, s[i]) >= 0 {
+			return i, 1
+		}
+	}
+	return -1, 0
+}
+
+// primaryDelimiter returns the delimiter used to rejoin lines.
+func (tb *TailBuffer) primaryDelimiter() string {
+	if tb.delimiterToken != "" {
+		return tb.delimiterToken
+	}
+	return string(tb.delimiters[:1])
+}
+
+// pendingContinues reports whether the pending line is a continuation line with WithContinuation,
+// to be joined to the last maintained line as it will be once completed, instead of being a line of its own.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) pendingContinues() bool {
+	return tb.continuation != nil && len(tb.lines) > 0 && tb.buffer.Len() > 0 && tb.continuation(tb.pendingText())
+}
+
+// pendingSeparate reports whether the pending line is shown as a line of its own.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) pendingSeparate() bool {
+	return tb.buffer.Len() > 0 && !tb.pendingHidden() && !tb.pendingContinues()
+}
+
+// appendLine adds a completed line to the maintained lines.
+// A continuation line is joined to the previous line instead.
+func (tb *TailBuffer) appendLine(l *line) {
+	if tb.continuation != nil && len(tb.lines) > 0 && tb.continuation(l.text) {
+		last := &tb.lines[len(tb.lines)-1]
+		tb.lineBytes += 1 + len(l.text) + len(l.term) - len(last.term)
+		tb.termBytes += len(l.term) - len(last.term)
+		if tb.maxRunes > 0 {
+			tb.runes += 1 + utf8.RuneCountInString(l.text)
+		}
+		last.text += "\n" + l.text
+		last.term = l.term
+		tb.notifySubscribers(l.text)
+		return
+	}
+	if tb.resetOnMarker != nil && tb.resetOnMarker(l.text) {
+		tb.clearLines()
+		if !tb.keepMarker {
+			tb.notifySubscribers(l.text)
+			return
+		}
+	}
+	if tb.sectionStart != nil {
+		l.sectionStart = tb.sectionStart(l.text)
+	}
+	if tb.spanBegin != nil {
+		if !tb.inSpan {
+			if !tb.spanBegin(l.text) {
+				// Lines outside spans are not maintained
+				tb.notifySubscribers(l.text)
+				return
+			}
+			l.spanBegin = true
+			tb.inSpan = true
+		}
+		if tb.spanEnd(l.text) {
+			tb.inSpan = false
+		}
+	}
+	if tb.timestamps || tb.timestampPrefix != "" {
+		now := tb.clock()
+		if tb.timestamps {
+			l.time = now
+		}
+		if tb.timestampPrefix != "" {
+			l.text = now.Format(tb.timestampPrefix) + " " + l.text
+		}
+	}
+	tb.pushLine(l)
+	tb.notifySubscribers(l.text)
+}
+
+// clearLines discards the maintained lines and the overflow state.
+func (tb *TailBuffer) clearLines() {
+	clear(tb.store[:cap(tb.store)])
+	tb.lines = tb.store[:0]
+	tb.meta.truncate(0)
+	tb.lineBytes = 0
+	tb.termBytes = 0
+	tb.runes = 0
+	tb.overflowed = false
+	tb.inSpan = false
+	tb.lastBlank = false
+}
+
+// pushLine appends l to the maintained lines.
+// When the backing array is full, the lines are moved to its front to reuse the space of evicted lines,
+// or if there is no such space, the backing array is grown, up to maxLines lines while they fit.
+func (tb *TailBuffer) pushLine(l *line) {
+	if len(tb.lines) == cap(tb.lines) {
+		if len(tb.lines) < cap(tb.store) {
+			n := copy(tb.store[:cap(tb.store)], tb.lines)
+			clear(tb.store[n:cap(tb.store)])
+			tb.lines = tb.store[:n]
+		} else {
+			size := max(2*len(tb.lines), 1)
+			if len(tb.lines) < tb.maxLines {
+				size = min(size, tb.maxLines)
+			}
+			grown := make([]storedLine, len(tb.lines), size)
+			copy(grown, tb.lines)
+			tb.lines = grown
+			tb.store = grown[:0]
+		}
+	}
+	tb.meta.push(l, len(tb.lines))
+	tb.lineBytes += len(l.text) + len(l.term)
+	tb.termBytes += len(l.term)
+	if tb.maxRunes > 0 {
+		tb.runes += utf8.RuneCountInString(l.text)
+	}
+	tb.lines = append(tb.lines, storedLine{
+		text:         l.text,
+		term:         l.term,
+		offset:       l.offset,
+		sectionStart: l.sectionStart,
+		spanBegin:    l.spanBegin,
+		first:        l.first,
+	})
+}
+
+// forgetLines subtracts the length of lines about to be removed from the maintained lines
+// from tb.lineBytes and tb.termBytes, and their runes from tb.runes.
+func (tb *TailBuffer) forgetLines(lines []storedLine) {
+	for _, l := range lines {
+		tb.lineBytes -= len(l.text) + len(l.term)
+		tb.termBytes -= len(l.term)
+		if tb.maxRunes > 0 {
+			tb.runes -= utf8.RuneCountInString(l.text)
+		}
+	}
+}
+
+// Grow grows the space for the maintained lines, if necessary, so that n more lines can be maintained
+// without reallocation, up to maxLines lines in total, e.g. before a known burst of writes.
+// It also grows the buffer for the pending line by the average length of the maintained lines.
+func (tb *TailBuffer) Grow(n int) {
 	tb.mu.Lock()
 	defer tb.mu.Unlock()
 
-	// Consider any unprocessed data in the current buffer
-	result := make([]string, len(tb.lines))
-	copy(result, tb.lines)
+	if size := min(len(tb.lines)+max(n, 0), tb.maxLines); size > cap(tb.store) {
+		grown := make([]storedLine, len(tb.lines), size)
+		copy(grown, tb.lines)
+		tb.lines = grown
+		tb.store = grown[:0]
+	}
+	if len(tb.lines) > 0 {
+		total := 0
+		for _, l := range tb.lines {
+			total += len(l.text)
+		}
+		tb.buffer.Grow(total / len(tb.lines))
+	}
+}
+
+// decode transcodes p to UTF-8 using the configured decoder.
+// Trailing bytes that do not form a complete character yet are kept until the next Write.
+func (tb *TailBuffer) decode(p []byte) ([]byte, error) {
+	src := append(tb.undecoded, p...)
+	tb.undecoded = nil
+
+	var out []byte
+	dst := make([]byte, 4*len(src)+utf8.UTFMax)
+	for len(src) > 0 {
+		nDst, nSrc, err := tb.decoder.Transform(dst, src, false)
+		out = append(out, dst[:nDst]...)
+		src = src[nSrc:]
+		if err == nil || errors.Is(err, transform.ErrShortDst) {
+			continue
+		}
+		if errors.Is(err, transform.ErrShortSrc) {
+			tb.undecoded = append(tb.undecoded, src...)
+			break
+		}
+		return nil, err
+	}
+
+	// Strip a leading BOM at the beginning of the stream
+	if !tb.bomChecked && len(out) > 0 {
+		tb.bomChecked = true
+		out = bytes.TrimPrefix(out, []byte(bom))
+	}
+
+	return out, nil
+}
+
+// lineAt returns the i-th maintained line with its metadata.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) lineAt(i int) line {
+	s := tb.lines[i]
+	l := line{
+		text:         s.text,
+		term:         s.term,
+		offset:       s.offset,
+		sectionStart: s.sectionStart,
+		spanBegin:    s.spanBegin,
+		first:        s.first,
+	}
+	tb.meta.fill(&l, i)
+	return l
+}
+
+// completedLines returns a copy of the maintained lines with their metadata, excluding the pending line.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) completedLines() []line {
+	result := make([]line, len(tb.lines))
+	for i := range tb.lines {
+		result[i] = tb.lineAt(i)
+	}
+	return result
+}
+
+// snapshot returns a copy of the maintained lines including the pending line.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) snapshot() []line {
+	result := tb.completedLines()
 
 	// Add any remaining data in the buffer as the last line
-	if tb.buffer.Len() > 0 {
-		result = append(result, tb.buffer.String())
+	if tb.buffer.Len() > 0 && !tb.pendingHidden() && tb.pendingContinues() {
+		result[len(result)-1].text += "\n" + tb.pendingText()
+	} else if tb.buffer.Len() > 0 && !tb.pendingHidden() {
+		result = append(result, line{text: tb.pendingText(), tag: tb.pendingTag, scope: tb.pendingScope, offset: tb.offset})
 		// Adjust if exceeding maxLines
-		if tb.maxLines > 0 && len(result) > tb.maxLines {
+		if tb.sectionStart == nil && tb.spanBegin == nil && tb.maxLines > 0 && len(result) > tb.maxLines {
 			result = result[len(result)-tb.maxLines:]
 		}
 	}
@@ -93,48 +927,505 @@ func (tb *TailBuffer) Lines() []string {
 	return result
 }
 
+// Lines returns the maintained lines as a slice.
+func (tb *TailBuffer) Lines() []string {
+	tb.mu.Lock()
+	ts := tb.textSnapshot()
+	tb.mu.Unlock()
+
+	return ts.all()
+}
+
+// QuotedLines returns the maintained lines quoted with strconv.Quote,
+// so that control characters, quotes and delimiters are escaped reversibly
+// and each line is safe to embed in another log line.
+func (tb *TailBuffer) QuotedLines() []string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	snapshot := tb.snapshot()
+	result := make([]string, len(snapshot))
+	for i, l := range snapshot {
+		result[i] = strconv.Quote(l.text)
+	}
+	return result
+}
+
+// Fields returns the maintained lines each split into fields by sep, e.g. "\t" for TSV.
+// If sep is empty, the lines are split around runs of whitespace like strings.Fields.
+// The lines are maintained as whole lines.
+func (tb *TailBuffer) Fields(sep string) [][]string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	snapshot := tb.snapshot()
+	result := make([][]string, len(snapshot))
+	for i, l := range snapshot {
+		if sep == "" {
+			result[i] = strings.Fields(l.text)
+			continue
+		}
+		result[i] = strings.Split(l.text, sep)
+	}
+	return result
+}
+
+// LinesWithin returns the maintained lines completed within d before now, read from the clock set by WithClock,
+// as a read-time filter over the lines maintained by count. The pending line is treated as completed now.
+// It requires WithTimestamps and returns an empty slice if it is not set.
+func (tb *TailBuffer) LinesWithin(d time.Duration) []string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	lines := []string{}
+	if !tb.timestamps {
+		return lines
+	}
+	now := tb.clock()
+	snapshot := tb.snapshot()
+	pending := tb.buffer.Len() > 0 && !tb.pendingHidden()
+	for i, l := range snapshot {
+		if pending && i == len(snapshot)-1 || now.Sub(l.time) <= d {
+			lines = append(lines, l.text)
+		}
+	}
+	return lines
+}
+
+// IndexBy returns the maintained lines indexed by the key returned by keyFn for each line, e.g. a request ID,
+// mapping each key to the most recent line with that key. Lines with an empty key are not indexed.
+func (tb *TailBuffer) IndexBy(keyFn func(line string) string) map[string]string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	result := map[string]string{}
+	for _, l := range tb.snapshot() {
+		if key := keyFn(l.text); key != "" {
+			result[key] = l.text
+		}
+	}
+	return result
+}
+
+// SplitAt returns the maintained lines split at index i into independent copies of [0:i] and [i:].
+// i is clamped to the range of the maintained lines.
+func (tb *TailBuffer) SplitAt(i int) (head, tail []string) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	snapshot := tb.snapshot()
+	i = min(max(i, 0), len(snapshot))
+	head = make([]string, i)
+	tail = make([]string, len(snapshot)-i)
+	for j, l := range snapshot {
+		if j < i {
+			head[j] = l.text
+		} else {
+			tail[j-i] = l.text
+		}
+	}
+	return head, tail
+}
+
+// Reduce folds the maintained lines into a single value, starting from init.
+// The lines are taken from a snapshot, so fn may safely call methods of tb.
+// The pending line is included, as with Lines.
+// The overflow marker is not included.
+func Reduce[T any](tb *TailBuffer, init T, fn func(acc T, line string) T) T {
+	tb.mu.Lock()
+	snapshot := tb.snapshot()
+	tb.mu.Unlock()
+
+	acc := init
+	for _, l := range snapshot {
+		acc = fn(acc, l.text)
+	}
+	return acc
+}
+
+// EachLine calls fn for each maintained line from the oldest to the newest, including the pending line,
+// stopping at the first error and returning it, e.g. to ship the lines to another logger one message per line.
+// The lines are taken from a snapshot, so fn may safely call methods of tb.
+func (tb *TailBuffer) EachLine(fn func(line string) error) error {
+	tb.mu.Lock()
+	snapshot := tb.snapshot()
+	tb.mu.Unlock()
+
+	for _, l := range snapshot {
+		if err := fn(l.text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ForEachReverse calls fn for each maintained line from the newest to the oldest, starting with the pending line
+// if any, until fn returns false. i is the index of the line as returned by Lines.
+// The lines are taken from a snapshot, so fn may safely call methods of tb.
+func (tb *TailBuffer) ForEachReverse(fn func(i int, line string) bool) {
+	tb.mu.Lock()
+	snapshot := tb.snapshot()
+	tb.mu.Unlock()
+
+	for i := len(snapshot) - 1; i >= 0; i-- {
+		if !fn(i, snapshot[i].text) {
+			return
+		}
+	}
+}
+
 // String returns the maintained lines joined with newlines as a string.
+// Line terminators are normalized to the primary delimiter ("\n" by default);
+// use RawBytes to get the original ones.
+// The result ends with the delimiter unless the last line is the pending line, i.e. the data
+// ended mid-line; with WithAlwaysTrailingNewline, it always ends with the delimiter unless empty.
 func (tb *TailBuffer) String() string {
+	tb.mu.Lock()
+	ts := tb.textSnapshot()
+	tb.mu.Unlock()
+
+	return ts.join(nil)
+}
+
+// textSnapshot is the texts of the lines output by String, taken under the lock
+// so that they can be joined after releasing it. The strings are immutable, so only their headers are copied.
+type textSnapshot struct {
+	// caption is the first line kept with WithKeepFirstLine to be prepended, if any.
+	caption    string
+	hasCaption bool
+	// marker is the overflow marker to be prepended, if any.
+	marker   string
+	texts    []string
+	delim    string
+	trailing bool
+}
+
+// textSnapshot returns the texts of the maintained lines including the pending line, as snapshot does.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) textSnapshot() textSnapshot {
+	pending := tb.pendingSeparate()
+	start := 0
+	if pending && tb.sectionStart == nil && tb.spanBegin == nil && tb.maxLines > 0 {
+		start = max(len(tb.lines)+1-tb.maxLines, 0)
+	}
+	ts := textSnapshot{
+		texts: make([]string, 0, len(tb.lines)-start+1),
+		delim: tb.primaryDelimiter(),
+		// If the last line is complete, the last write ended with a newline
+		trailing: tb.trailingNewline || tb.buffer.Len() == 0 || tb.pendingHidden(),
+	}
+	for _, l := range tb.lines[start:] {
+		ts.texts = append(ts.texts, l.text)
+	}
+	if pending {
+		ts.texts = append(ts.texts, tb.pendingText())
+	} else if tb.buffer.Len() > 0 && !tb.pendingHidden() && tb.pendingContinues() {
+		ts.texts[len(ts.texts)-1] += "\n" + tb.pendingText()
+	}
+	// Prepend the first line unless it is still maintained
+	if tb.prependFirst && tb.hasFirstLine && (start == len(tb.lines) || !tb.lines[start].first) {
+		ts.caption = tb.firstLine
+		ts.hasCaption = true
+	}
+	// The marker annotates the lines rendered, so it is omitted when there are none
+	if tb.overflowMarker != "" && tb.overflowed && len(ts.texts) > 0 {
+		ts.marker = tb.overflowMarker
+	}
+	return ts
+}
+
+// all returns the lines output by String, including the first line and the overflow marker prepended, as Lines does.
+func (ts textSnapshot) all() []string {
+	if !ts.hasCaption && ts.marker == "" {
+		return ts.texts
+	}
+	result := make([]string, 0, len(ts.texts)+2)
+	if ts.hasCaption {
+		result = append(result, ts.caption)
+	}
+	if ts.marker != "" {
+		result = append(result, ts.marker)
+	}
+	return append(result, ts.texts...)
+}
+
+// join joins the lines as String does.
+// If classify is not nil, each line is wrapped in the ANSI escape codes of the color it returns.
+func (ts textSnapshot) join(classify func(line string) Color) string {
+	var sb strings.Builder
+	if ts.hasCaption {
+		sb.WriteString(ts.caption)
+		sb.WriteString(ts.delim)
+	}
+	if len(ts.texts) == 0 {
+		return sb.String()
+	}
+
+	if ts.marker != "" {
+		sb.WriteString(ts.marker)
+		sb.WriteString(ts.delim)
+	}
+	for i, text := range ts.texts {
+		if i > 0 {
+			sb.WriteString(ts.delim)
+		}
+		if classify != nil {
+			sb.WriteString(classify(text).wrap(text))
+			continue
+		}
+		sb.WriteString(text)
+	}
+	if ts.trailing {
+		sb.WriteString(ts.delim)
+	}
+	return sb.String()
+}
+
+// FirstLine returns the first completed line kept with WithKeepFirstLine.
+// It returns an empty string if no line has been completed yet or WithKeepFirstLine is not set;
+// the pending line is not captured until it is completed.
+func (tb *TailBuffer) FirstLine() string {
 	tb.mu.Lock()
 	defer tb.mu.Unlock()
 
-	if len(tb.lines) == 0 && tb.buffer.Len() == 0 {
-		return ""
+	return tb.firstLine
+}
+
+// RawBytes returns the maintained lines as they appeared in the input,
+// including the original line terminators (e.g. "\n" or "\r\n").
+// Unlike String and Bytes, no delimiter is added after the pending line, even with WithAlwaysTrailingNewline,
+// and neither the overflow marker nor the first line kept with WithKeepFirstLine is included.
+func (tb *TailBuffer) RawBytes() []byte {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	b := []byte{}
+	for _, l := range tb.snapshot() {
+		b = append(b, l.text...)
+		b = append(b, l.term...)
 	}
+	return b
+}
 
-	// Create a copy of lines
-	result := make([]string, len(tb.lines))
-	copy(result, tb.lines)
+// AtLineBoundary reports whether there is no pending line, i.e. the last write ended with a delimiter.
+func (tb *TailBuffer) AtLineBoundary() bool {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 
-	// Check if there's data in buffer
-	hasTrailingNewline := false
-	if tb.buffer.Len() > 0 {
-		result = append(result, tb.buffer.String())
-		// Adjust if exceeding maxLines
-		if tb.maxLines > 0 && len(result) > tb.maxLines {
-			result = result[len(result)-tb.maxLines:]
+	return tb.buffer.Len() == 0 && len(tb.undecoded) == 0
+}
+
+// Checksum returns the digest of all bytes written, as they were passed to Write, with the hash set by WithChecksum.
+// It is independent of the maintained lines and is not affected by eviction or Reset.
+// It returns nil if WithChecksum is not set.
+func (tb *TailBuffer) Checksum() []byte {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if tb.checksum == nil {
+		return nil
+	}
+	return tb.checksum.Sum(nil)
+}
+
+// ContentHash returns the 64-bit FNV-1a hash of the maintained lines in the form returned by String,
+// e.g. to detect changes or compare buffers cheaply. Unlike Checksum, it depends only on the maintained content,
+// not on how it was written.
+func (tb *TailBuffer) ContentHash() uint64 {
+	tb.mu.Lock()
+	ts := tb.textSnapshot()
+	tb.mu.Unlock()
+
+	h := fnv.New64a()
+	_, _ = io.WriteString(h, ts.join(nil))
+	return h.Sum64()
+}
+
+// Reset discards all maintained lines, including those of the error tail and the named tails, the pending line and the overflow state.
+// It is atomic with respect to Write: the data of a single Write is either discarded as a whole or kept as a whole.
+// The pending line is discarded intentionally, so a line written across multiple Write calls can lose its beginning
+// if Reset is called in between, as with ReadFromContext which writes each chunk read separately.
+// It does nothing if the TailBuffer is frozen by Freeze.
+func (tb *TailBuffer) Reset() {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if tb.frozen {
+		return
+	}
+	tb.reset()
+}
+
+// reset discards the state as Reset does.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) reset() {
+	tb.clearLines()
+	tb.buffer.Reset()
+	tb.undecoded = nil
+	if tb.json != nil {
+		*tb.json = jsonSplitter{}
+	}
+	if tb.errorTail != nil {
+		tb.errorTail.lines = nil
+	}
+	for _, st := range tb.namedTails {
+		st.lines = nil
+	}
+	tb.lastText = ""
+	tb.hasLastText = false
+}
+
+// ReplaceInLines replaces all occurrences of old with new in each completed line, e.g. to strip stray "\r".
+// It does not affect the pending line, nor lines written later.
+func (tb *TailBuffer) ReplaceInLines(old, new string) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if tb.frozen {
+		return
+	}
+	for i := range tb.lines {
+		text := strings.ReplaceAll(tb.lines[i].text, old, new)
+		tb.lineBytes += len(text) - len(tb.lines[i].text)
+		if tb.maxRunes > 0 {
+			tb.runes += utf8.RuneCountInString(text) - utf8.RuneCountInString(tb.lines[i].text)
 		}
-	} else if len(tb.lines) > 0 {
-		// If buffer is empty, it means the last write ended with a newline
-		hasTrailingNewline = true
+		tb.lines[i].text = text
 	}
+}
 
-	str := strings.Join(result, "\n")
-	if hasTrailingNewline && len(result) > 0 {
-		str += "\n"
+// ResetOverflow clears the overflow state while keeping the maintained lines.
+func (tb *TailBuffer) ResetOverflow() {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if tb.frozen {
+		return
 	}
-	return str
+	tb.overflowed = false
 }
 
-// Bytes returns the maintained lines joined with newlines as a byte slice.
+// Bytes returns the maintained lines as a byte slice, the same as String.
+// Use RawBytes for the lines as they were written instead.
 func (tb *TailBuffer) Bytes() []byte {
 	return []byte(tb.String())
 }
 
 // WriteTo implements the io.WriterTo interface.
-// It writes the maintained lines to the specified Writer.
+// It writes the maintained lines to the specified Writer, as returned by String.
 func (tb *TailBuffer) WriteTo(w io.Writer) (n int64, err error) {
 	data := tb.Bytes()
 	written, err := w.Write(data)
 	return int64(written), err
 }
+
+// WriteToLines writes the maintained lines to w like WriteTo, but with a separate Write call for each line
+// followed by the delimiter, so that w receives per-line boundaries. The pending line is followed by the delimiter too.
+// It stops at the first error, returning the number of bytes written so far; a short write is reported as io.ErrShortWrite.
+func (tb *TailBuffer) WriteToLines(w io.Writer) (n int64, err error) {
+	tb.mu.Lock()
+	ts := tb.textSnapshot()
+	tb.mu.Unlock()
+
+	for _, text := range ts.all() {
+		b := []byte(text + ts.delim)
+		written, err := w.Write(b)
+		n += int64(written)
+		if err != nil {
+			return n, err
+		}
+		if written != len(b) {
+			return n, io.ErrShortWrite
+		}
+	}
+	return n, nil
+}
+
+// NullSeparatedReader returns a reader over the maintained lines, each followed by a NUL byte
+// regardless of the delimiter, for tools that read NUL-separated records such as `xargs -0`.
+// The lines are read as they were maintained when it was called.
+func (tb *TailBuffer) NullSeparatedReader() io.Reader {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	b := []byte{}
+	for _, l := range tb.snapshot() {
+		b = append(b, l.text...)
+		b = append(b, 0)
+	}
+	return bytes.NewReader(b)
+}
+
+// Freeze makes the TailBuffer read-only, e.g. to publish it as a static snapshot while code paths
+// still hold it as a writer. After Freeze, Write returns ErrFrozen, methods modifying the maintained lines
+// such as Reset, ReplaceInLines, ResetOverflow and SetMaxLines do nothing, and FlushSnapshotReset
+// returns the snapshot without discarding the lines. The pending line is kept pending.
+// Reading methods keep working. Freeze is idempotent and safe to call concurrently.
+// Use it with FromLines to construct a read-only TailBuffer.
+func (tb *TailBuffer) Freeze() {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.frozen = true
+	if tb.pendingFlush != nil && tb.pendingFlush.timer != nil {
+		tb.pendingFlush.timer.Stop()
+	}
+}
+
+// Frozen reports whether Freeze has been called.
+func (tb *TailBuffer) Frozen() bool {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	return tb.frozen
+}
+
+// Close implements the io.Closer interface.
+// After Close, Write returns ErrClosed, while the maintained lines remain accessible.
+// With WithDrainOnClose, the first call sends the maintained lines, including the pending line,
+// to the channel in order and then closes it. If the timeout elapses before all lines are sent,
+// the rest of them are dropped and context.DeadlineExceeded is returned.
+// With WithFileRotation, it flushes and closes the files, and returns the first error writing them.
+// Subsequent calls do nothing and return nil.
+func (tb *TailBuffer) Close() (err error) {
+	tb.mu.Lock()
+	if tb.closed {
+		tb.mu.Unlock()
+		return nil
+	}
+	tb.closed = true
+	if tb.evictions != nil && tb.evictions.spill != nil {
+		spill := tb.evictions.spill
+		defer func() {
+			// Deliver the evictions not delivered yet before closing the files
+			tb.deliverEvictions()
+			err = errors.Join(err, spill.close())
+		}()
+	}
+	if tb.pendingFlush != nil && tb.pendingFlush.timer != nil {
+		tb.pendingFlush.timer.Stop()
+	}
+	if tb.drainCh == nil {
+		tb.mu.Unlock()
+		return nil
+	}
+	snapshot := tb.snapshot()
+	tb.mu.Unlock()
+
+	// Send outside the lock so that the receiver can use the buffer
+	defer close(tb.drainCh)
+	var timeout <-chan time.Time
+	if tb.drainTimeout > 0 {
+		timer := time.NewTimer(tb.drainTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	for _, l := range snapshot {
+		select {
+		case tb.drainCh <- l.text:
+		case <-timeout:
+			return context.DeadlineExceeded
+		}
+	}
+	return nil
+}
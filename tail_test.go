@@ -2,8 +2,20 @@ package tail
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
 )
 
 func TestTailBuffer_Write(t *testing.T) {
@@ -87,6 +99,67 @@ func TestTailBuffer_Write(t *testing.T) {
 	}
 }
 
+func TestTailBuffer_WriteEmpty(t *testing.T) {
+	tests := []struct {
+		name           string
+		limit          int
+		writes         [][]byte
+		expectedLines  []string
+		expectedString string
+	}{
+		{
+			name:           "nil",
+			limit:          3,
+			writes:         [][]byte{nil},
+			expectedLines:  []string{},
+			expectedString: "",
+		},
+		{
+			name:           "empty slice",
+			limit:          3,
+			writes:         [][]byte{{}},
+			expectedLines:  []string{},
+			expectedString: "",
+		},
+		{
+			name:           "lone newline",
+			limit:          3,
+			writes:         [][]byte{[]byte("\n")},
+			expectedLines:  []string{""},
+			expectedString: "\n",
+		},
+		{
+			name:           "empty writes between lines",
+			limit:          3,
+			writes:         [][]byte{[]byte("line1\nli"), nil, {}, []byte("ne2\n"), nil},
+			expectedLines:  []string{"line1", "line2"},
+			expectedString: "line1\nline2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(tt.limit)
+			for _, data := range tt.writes {
+				n, err := tw.Write(data)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if n != len(data) {
+					t.Errorf("expected %d bytes written, got %d", len(data), n)
+				}
+			}
+
+			if result := tw.Lines(); !slices.Equal(result, tt.expectedLines) {
+				t.Errorf("Lines(): expected %q, got %q", tt.expectedLines, result)
+			}
+			if result := tw.String(); result != tt.expectedString {
+				t.Errorf("String(): expected %q, got %q", tt.expectedString, result)
+			}
+		})
+	}
+}
+
 func TestTailBuffer_Output(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -238,3 +311,2344 @@ func BenchmarkTailBuffer_WriteLongLines(b *testing.B) {
 		_, _ = tw.Write(data)
 	}
 }
+
+func TestTailBuffer_WithDecoder(t *testing.T) {
+	utf16le := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)
+	encode := func(t *testing.T, s string) []byte {
+		t.Helper()
+		b, err := utf16le.NewEncoder().Bytes([]byte(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	tests := []struct {
+		name     string
+		enc      encoding.Encoding
+		writes   func(t *testing.T) [][]byte
+		expected []string
+	}{
+		{
+			name: "utf-8 with bom",
+			enc:  unicode.UTF8,
+			writes: func(t *testing.T) [][]byte {
+				return [][]byte{[]byte("\xef\xbb\xbfline1\nline2\n")}
+			},
+			expected: []string{"line1", "line2"},
+		},
+		{
+			name: "utf-16le with bom",
+			enc:  utf16le,
+			writes: func(t *testing.T) [][]byte {
+				return [][]byte{encode(t, "line1\nこんにちは\n")}
+			},
+			expected: []string{"line1", "こんにちは"},
+		},
+		{
+			name: "utf-16le split in the middle of a character",
+			enc:  utf16le,
+			writes: func(t *testing.T) [][]byte {
+				b := encode(t, "line1\nline2\n")
+				return [][]byte{b[:3], b[3:13], b[13:]}
+			},
+			expected: []string{"line1", "line2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(3, WithDecoder(tt.enc))
+			for _, data := range tt.writes(t) {
+				n, err := tw.Write(data)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if n != len(data) {
+					t.Errorf("expected %d bytes written, got %d", len(data), n)
+				}
+			}
+
+			result := tw.Lines()
+			if len(result) != len(tt.expected) {
+				t.Fatalf("expected %d lines, got %d: %q", len(tt.expected), len(result), result)
+			}
+			for i, line := range result {
+				if line != tt.expected[i] {
+					t.Errorf("line %d: expected '%s', got '%s'", i, tt.expected[i], line)
+				}
+			}
+		})
+	}
+}
+
+func TestTailBuffer_RawBytes(t *testing.T) {
+	tests := []struct {
+		name           string
+		limit          int
+		input          string
+		expectedLines  []string
+		expectedString string
+		expectedRaw    string
+	}{
+		{
+			name:           "lf",
+			limit:          3,
+			input:          "line1\nline2\nline3\nline4\n",
+			expectedLines:  []string{"line2", "line3", "line4"},
+			expectedString: "line2\nline3\nline4\n",
+			expectedRaw:    "line2\nline3\nline4\n",
+		},
+		{
+			name:           "crlf",
+			limit:          3,
+			input:          "line1\r\nline2\r\nline3\r\nline4\r\n",
+			expectedLines:  []string{"line2", "line3", "line4"},
+			expectedString: "line2\nline3\nline4\n",
+			expectedRaw:    "line2\r\nline3\r\nline4\r\n",
+		},
+		{
+			name:           "mixed with no newline at end",
+			limit:          3,
+			input:          "line1\r\nline2\nline3",
+			expectedLines:  []string{"line1", "line2", "line3"},
+			expectedString: "line1\nline2\nline3",
+			expectedRaw:    "line1\r\nline2\nline3",
+		},
+		{
+			name:           "empty buffer",
+			limit:          3,
+			input:          "",
+			expectedLines:  []string{},
+			expectedString: "",
+			expectedRaw:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(tt.limit)
+			if _, err := tw.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result := tw.Lines(); !slices.Equal(result, tt.expectedLines) {
+				t.Errorf("Lines(): expected %q, got %q", tt.expectedLines, result)
+			}
+			if result := tw.String(); result != tt.expectedString {
+				t.Errorf("String(): expected %q, got %q", tt.expectedString, result)
+			}
+			if result := tw.RawBytes(); string(result) != tt.expectedRaw {
+				t.Errorf("RawBytes(): expected %q, got %q", tt.expectedRaw, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_BytesAndRawBytes(t *testing.T) {
+	tw := New(2, WithAlwaysTrailingNewline(), WithOverflowMarker("..."))
+	if _, err := tw.Write([]byte("line1\r\nline2\r\nline3\r\npen")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected, result := "...\nline3\npen\n", string(tw.Bytes()); result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+	if expected, result := "line3\r\npen", string(tw.RawBytes()); result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestReduce(t *testing.T) {
+	tw := New(3)
+	if _, err := tw.Write([]byte("1\n2\nerror: 3\n4\nerror: 5")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	count := Reduce(tw, 0, func(acc int, line string) int {
+		if strings.HasPrefix(line, "error:") {
+			acc++
+		}
+		return acc
+	})
+	if count != 2 {
+		t.Errorf("expected 2, got %d", count)
+	}
+
+	joined := Reduce(tw, "", func(acc string, line string) string {
+		return acc + "[" + line + "]"
+	})
+	if expected := "[error: 3][4][error: 5]"; joined != expected {
+		t.Errorf("expected '%s', got '%s'", expected, joined)
+	}
+}
+
+func TestTailBuffer_WithContinuation(t *testing.T) {
+	indented := func(line string) bool {
+		return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+	}
+
+	tests := []struct {
+		name     string
+		limit    int
+		writes   []string
+		expected []string
+	}{
+		{
+			name:   "stack trace",
+			limit:  2,
+			writes: []string{"start\n", "Exception in thread \"main\"\n\tat Foo.bar(Foo.java:1)\n\tat Foo.main(Foo.java:2)\n", "done\n"},
+			expected: []string{
+				"Exception in thread \"main\"\n\tat Foo.bar(Foo.java:1)\n\tat Foo.main(Foo.java:2)",
+				"done",
+			},
+		},
+		{
+			name:     "continuation across writes",
+			limit:    2,
+			writes:   []string{"panic: boom\n", "\ngoroutine 1:\n", "  main.main()\n  ", "  /main.go:5\n"},
+			expected: []string{"", "goroutine 1:\n  main.main()\n    /main.go:5"},
+		},
+		{
+			name:     "pending continuation",
+			limit:    2,
+			writes:   []string{"e1\ne2\ne3\n\tat c"},
+			expected: []string{"e2", "e3\n\tat c"},
+		},
+		{
+			name:     "continuation without previous line",
+			limit:    2,
+			writes:   []string{"  indented\nline\n"},
+			expected: []string{"  indented", "line"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(tt.limit, WithContinuation(indented))
+			for _, data := range tt.writes {
+				if _, err := tw.Write([]byte(data)); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			if result := tw.Lines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_WithOverflowMarker(t *testing.T) {
+	const marker = "... (earlier output omitted) ..."
+
+	tests := []struct {
+		name           string
+		limit          int
+		opts           []Option
+		input          string
+		reset          func(tw *TailBuffer)
+		expectedLines  []string
+		expectedString string
+	}{
+		{
+			name:           "not overflowed",
+			limit:          3,
+			input:          "line1\nline2\nline3\n",
+			expectedLines:  []string{"line1", "line2", "line3"},
+			expectedString: "line1\nline2\nline3\n",
+		},
+		{
+			name:           "overflowed",
+			limit:          3,
+			input:          "line1\nline2\nline3\nline4\n",
+			expectedLines:  []string{marker, "line2", "line3", "line4"},
+			expectedString: marker + "\nline2\nline3\nline4\n",
+		},
+		{
+			name:           "reset overflow",
+			limit:          3,
+			input:          "line1\nline2\nline3\nline4\n",
+			reset:          (*TailBuffer).ResetOverflow,
+			expectedLines:  []string{"line2", "line3", "line4"},
+			expectedString: "line2\nline3\nline4\n",
+		},
+		{
+			name:           "reset",
+			limit:          3,
+			input:          "line1\nline2\nline3\nline4\nli",
+			reset:          (*TailBuffer).Reset,
+			expectedLines:  []string{},
+			expectedString: "",
+		},
+		{
+			name:           "no lines output",
+			limit:          3,
+			opts:           []Option{WithMemoryCap(200), WithExcludePending()},
+			input:          "line1\nline2\nline3\nline4\n" + strings.Repeat("x", 150),
+			expectedLines:  []string{},
+			expectedString: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(tt.limit, append([]Option{WithOverflowMarker(marker)}, tt.opts...)...)
+			if _, err := tw.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.reset != nil {
+				tt.reset(tw)
+			}
+
+			if result := tw.Lines(); !slices.Equal(result, tt.expectedLines) {
+				t.Errorf("Lines(): expected %q, got %q", tt.expectedLines, result)
+			}
+			if result := tw.String(); result != tt.expectedString {
+				t.Errorf("String(): expected %q, got %q", tt.expectedString, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_WithTimestampPrefix(t *testing.T) {
+	tw := New(3, WithTimestampPrefix(time.RFC3339Nano))
+	before := time.Now()
+	for _, data := range []string{"line1\nli", "ne2", "\nline3"} {
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	after := time.Now()
+
+	result := tw.Lines()
+	expected := []string{"line1", "line2", "line3"}
+	if len(result) != len(expected) {
+		t.Fatalf("expected %d lines, got %d: %q", len(expected), len(result), result)
+	}
+	for i, line := range result[:2] {
+		ts, text, ok := strings.Cut(line, " ")
+		if !ok {
+			t.Fatalf("line %d: no timestamp prefix: %q", i, line)
+		}
+		if text != expected[i] {
+			t.Errorf("line %d: expected '%s', got '%s'", i, expected[i], text)
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			t.Fatalf("line %d: %v", i, err)
+		}
+		if parsed.Before(before) || parsed.After(after) {
+			t.Errorf("line %d: timestamp %v out of range", i, parsed)
+		}
+	}
+	// The pending line is not prefixed until it is completed
+	if result[2] != "line3" {
+		t.Errorf("expected pending line 'line3', got '%s'", result[2])
+	}
+}
+
+func TestTailBuffer_WithDelimiters(t *testing.T) {
+	tests := []struct {
+		name           string
+		limit          int
+		delims         []byte
+		writes         []string
+		expectedLines  []string
+		expectedString string
+		expectedRaw    string
+	}{
+		{
+			name:           "newline and semicolon",
+			limit:          5,
+			delims:         []byte{'\n', ';'},
+			writes:         []string{"a;b\nc;", "d\n"},
+			expectedLines:  []string{"a", "b", "c", "d"},
+			expectedString: "a\nb\nc\nd\n",
+			expectedRaw:    "a;b\nc;d\n",
+		},
+		{
+			name:           "semicolon as primary delimiter",
+			limit:          3,
+			delims:         []byte{';', '\n'},
+			writes:         []string{"a;b\nc;d"},
+			expectedLines:  []string{"b", "c", "d"},
+			expectedString: "b;c;d",
+			expectedRaw:    "b\nc;d",
+		},
+		{
+			name:           "chunk ending before a delimiter",
+			limit:          5,
+			delims:         []byte{'\n', ';'},
+			writes:         []string{"a", ";b", ";", "\n"},
+			expectedLines:  []string{"a", "b", ""},
+			expectedString: "a\nb\n\n",
+			expectedRaw:    "a;b;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(tt.limit, WithDelimiters(tt.delims...))
+			for _, data := range tt.writes {
+				if _, err := tw.Write([]byte(data)); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			if result := tw.Lines(); !slices.Equal(result, tt.expectedLines) {
+				t.Errorf("Lines(): expected %q, got %q", tt.expectedLines, result)
+			}
+			if result := tw.String(); result != tt.expectedString {
+				t.Errorf("String(): expected %q, got %q", tt.expectedString, result)
+			}
+			if result := tw.RawBytes(); string(result) != tt.expectedRaw {
+				t.Errorf("RawBytes(): expected %q, got %q", tt.expectedRaw, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_WithRequireUTF8(t *testing.T) {
+	tests := []struct {
+		name     string
+		writes   []string
+		wantErr  []bool
+		expected []string
+	}{
+		{
+			name:     "valid",
+			writes:   []string{"line1\n", "こんにちは\n"},
+			wantErr:  []bool{false, false},
+			expected: []string{"line1", "こんにちは"},
+		},
+		{
+			name:     "invalid line is dropped",
+			writes:   []string{"line1\nbad\xff\nline3\n", "line4\n"},
+			wantErr:  []bool{true, false},
+			expected: []string{"line1", "line3", "line4"},
+		},
+		{
+			name:     "checked on completion",
+			writes:   []string{"line1\n\xe3\x81", "\x93\n", "\xe3\x81", "\n"},
+			wantErr:  []bool{false, false, false, true},
+			expected: []string{"line1", "こ"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(5, WithRequireUTF8())
+			for i, data := range tt.writes {
+				n, err := tw.Write([]byte(data))
+				if n != len(data) {
+					t.Errorf("expected %d bytes written, got %d", len(data), n)
+				}
+				if tt.wantErr[i] {
+					if !errors.Is(err, ErrInvalidUTF8) {
+						t.Errorf("write %d: expected ErrInvalidUTF8, got %v", i, err)
+					}
+				} else if err != nil {
+					t.Errorf("write %d: unexpected error: %v", i, err)
+				}
+			}
+
+			if result := tw.Lines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_WithExcludePending(t *testing.T) {
+	tests := []struct {
+		name           string
+		limit          int
+		input          string
+		expectedLines  []string
+		expectedString string
+	}{
+		{
+			name:           "pending line",
+			limit:          3,
+			input:          "line1\nline2\nline3\nli",
+			expectedLines:  []string{"line1", "line2", "line3"},
+			expectedString: "line1\nline2\nline3\n",
+		},
+		{
+			name:           "no pending line",
+			limit:          3,
+			input:          "line1\nline2\n",
+			expectedLines:  []string{"line1", "line2"},
+			expectedString: "line1\nline2\n",
+		},
+		{
+			name:           "only pending line",
+			limit:          3,
+			input:          "single",
+			expectedLines:  []string{},
+			expectedString: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(tt.limit, WithExcludePending())
+			if _, err := tw.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result := tw.Lines(); !slices.Equal(result, tt.expectedLines) {
+				t.Errorf("Lines(): expected %q, got %q", tt.expectedLines, result)
+			}
+			if result := tw.String(); result != tt.expectedString {
+				t.Errorf("String(): expected %q, got %q", tt.expectedString, result)
+			}
+		})
+	}
+}
+
+func BenchmarkTailBuffer_WriteCompleteLine(b *testing.B) {
+	tw := New(1000)
+	data := []byte("This is a benchmark test line\n")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = tw.Write(data)
+	}
+}
+
+func BenchmarkTailBuffer_WritePartialLine(b *testing.B) {
+	tw := New(1000)
+	data := [][]byte{[]byte("This is a benchmark "), []byte("test line\n")}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = tw.Write(data[i%2])
+	}
+}
+
+func TestTailBuffer_SplitAt(t *testing.T) {
+	tests := []struct {
+		name         string
+		i            int
+		expectedHead []string
+		expectedTail []string
+	}{
+		{
+			name:         "middle",
+			i:            1,
+			expectedHead: []string{"line2"},
+			expectedTail: []string{"line3", "line4"},
+		},
+		{
+			name:         "negative index",
+			i:            -1,
+			expectedHead: []string{},
+			expectedTail: []string{"line2", "line3", "line4"},
+		},
+		{
+			name:         "index out of range",
+			i:            5,
+			expectedHead: []string{"line2", "line3", "line4"},
+			expectedTail: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(3)
+			if _, err := tw.Write([]byte("line1\nline2\nline3\nline4")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			head, tail := tw.SplitAt(tt.i)
+			if !slices.Equal(head, tt.expectedHead) {
+				t.Errorf("head: expected %q, got %q", tt.expectedHead, head)
+			}
+			if !slices.Equal(tail, tt.expectedTail) {
+				t.Errorf("tail: expected %q, got %q", tt.expectedTail, tail)
+			}
+
+			// Appending to head must not overwrite tail
+			_ = append(head, "x")
+			if !slices.Equal(tail, tt.expectedTail) {
+				t.Errorf("tail was modified: %q", tail)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_WithCollapseRuns(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxRun   int
+		input    string
+		expected []string
+	}{
+		{
+			name:     "separator line",
+			maxRun:   3,
+			input:    strings.Repeat("-", 1000) + "\n",
+			expected: []string{"---[+997]"},
+		},
+		{
+			name:     "multiple runs",
+			maxRun:   2,
+			input:    "aaaab bb cccc\n",
+			expected: []string{"aa[+2]b bb cc[+2]"},
+		},
+		{
+			name:     "multibyte characters",
+			maxRun:   2,
+			input:    "=====あああああ=\n",
+			expected: []string{"==[+3]ああ[+3]="},
+		},
+		{
+			name:     "no long runs",
+			maxRun:   3,
+			input:    "hello\n",
+			expected: []string{"hello"},
+		},
+		{
+			name:     "pending line is not collapsed",
+			maxRun:   3,
+			input:    "aaaaa",
+			expected: []string{"aaaaa"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(3, WithCollapseRuns(tt.maxRun))
+			if _, err := tw.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result := tw.Lines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_EmptyContract(t *testing.T) {
+	tests := []struct {
+		name           string
+		prepare        func(tw *TailBuffer)
+		expectedLines  []string
+		expectedString string
+	}{
+		{
+			name:           "no writes",
+			prepare:        func(tw *TailBuffer) {},
+			expectedLines:  []string{},
+			expectedString: "",
+		},
+		{
+			name: "after reset",
+			prepare: func(tw *TailBuffer) {
+				_, _ = tw.Write([]byte("line1\nline2"))
+				tw.Reset()
+			},
+			expectedLines:  []string{},
+			expectedString: "",
+		},
+		{
+			name: "empty write",
+			prepare: func(tw *TailBuffer) {
+				_, _ = tw.Write(nil)
+			},
+			expectedLines:  []string{},
+			expectedString: "",
+		},
+		{
+			name: "only a newline",
+			prepare: func(tw *TailBuffer) {
+				_, _ = tw.Write([]byte("\n"))
+			},
+			expectedLines:  []string{""},
+			expectedString: "\n",
+		},
+		{
+			name: "whitespace only",
+			prepare: func(tw *TailBuffer) {
+				_, _ = tw.Write([]byte(" \t"))
+			},
+			expectedLines:  []string{" \t"},
+			expectedString: " \t",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(3)
+			tt.prepare(tw)
+
+			lines := tw.Lines()
+			if lines == nil {
+				t.Error("Lines(): expected non-nil slice")
+			}
+			if !slices.Equal(lines, tt.expectedLines) {
+				t.Errorf("Lines(): expected %q, got %q", tt.expectedLines, lines)
+			}
+			if result := tw.String(); result != tt.expectedString {
+				t.Errorf("String(): expected %q, got %q", tt.expectedString, result)
+			}
+			if result := tw.Bytes(); result == nil || string(result) != tt.expectedString {
+				t.Errorf("Bytes(): expected non-nil %q, got %#v", tt.expectedString, result)
+			}
+			if result := tw.RawBytes(); result == nil || string(result) != tt.expectedString {
+				t.Errorf("RawBytes(): expected non-nil %q, got %#v", tt.expectedString, result)
+			}
+			head, tail := tw.SplitAt(0)
+			if head == nil || tail == nil {
+				t.Error("SplitAt(): expected non-nil slices")
+			}
+			if result := tw.Sections(); result == nil {
+				t.Error("Sections(): expected non-nil slice")
+			}
+			if result, err := tw.CSVRecords(); err != nil || result == nil {
+				t.Errorf("CSVRecords(): expected non-nil slice, got %#v, %v", result, err)
+			}
+
+			var buf bytes.Buffer
+			n, err := tw.WriteTo(&buf)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tt.expectedString || n != int64(len(tt.expectedString)) {
+				t.Errorf("WriteTo(): expected %q, got %q (%d bytes)", tt.expectedString, buf.String(), n)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_WithChecksum(t *testing.T) {
+	input := "line1\nline2\nline3\nline4\n"
+	tw := New(2, WithChecksum(sha256.New()))
+	for _, data := range []string{input[:8], input[8:]} {
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	tw.Reset()
+
+	expected := sha256.Sum256([]byte(input))
+	if result := tw.Checksum(); !bytes.Equal(result, expected[:]) {
+		t.Errorf("expected %x, got %x", expected, result)
+	}
+
+	if result := New(2).Checksum(); result != nil {
+		t.Errorf("expected nil, got %x", result)
+	}
+}
+
+func TestFromLines(t *testing.T) {
+	tests := []struct {
+		name           string
+		limit          int
+		lines          []string
+		expectedLines  []string
+		expectedString string
+	}{
+		{
+			name:           "fewer lines than limit",
+			limit:          3,
+			lines:          []string{"line1", "line2"},
+			expectedLines:  []string{"line1", "line2"},
+			expectedString: "line1\nline2\n",
+		},
+		{
+			name:           "more lines than limit",
+			limit:          2,
+			lines:          []string{"line1", "line2", "line3"},
+			expectedLines:  []string{"line2", "line3"},
+			expectedString: "line2\nline3\n",
+		},
+		{
+			name:           "zero lines",
+			limit:          0,
+			lines:          []string{"line1"},
+			expectedLines:  []string{},
+			expectedString: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := FromLines(tt.limit, tt.lines)
+			if result := tw.Lines(); !slices.Equal(result, tt.expectedLines) {
+				t.Errorf("Lines(): expected %q, got %q", tt.expectedLines, result)
+			}
+			if result := tw.String(); result != tt.expectedString {
+				t.Errorf("String(): expected %q, got %q", tt.expectedString, result)
+			}
+		})
+	}
+
+	t.Run("continue writing", func(t *testing.T) {
+		lines := []string{"line1", "line2"}
+		tw := FromLines(2, lines)
+		lines[0] = "modified"
+		if _, err := tw.Write([]byte("line3\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected, result := []string{"line2", "line3"}, tw.Lines(); !slices.Equal(result, expected) {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	})
+}
+
+func TestTailBuffer_QuotedLines(t *testing.T) {
+	tw := New(3, WithDelimiters(';'))
+	if _, err := tw.Write([]byte("plain;tab\there;say \"hi\"\nnext;\x1b[31mred")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{`"tab\there"`, `"say \"hi\"\nnext"`, `"\x1b[31mred"`}
+	result := tw.QuotedLines()
+	if !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+
+	// Quoting is reversible
+	for i, q := range result {
+		s, err := strconv.Unquote(q)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if lines := tw.Lines(); s != lines[i] {
+			t.Errorf("expected %q, got %q", lines[i], s)
+		}
+	}
+}
+
+func TestTailBuffer_WriteByte(t *testing.T) {
+	tw := New(3)
+	var _ io.ByteWriter = tw
+
+	// Mix WriteByte with Write
+	if _, err := tw.Write([]byte("line1\nli")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, c := range []byte("ne2\r\nline3\nline4\npen") {
+		if err := tw.WriteByte(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := tw.Write([]byte("ding")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected, result := []string{"line3", "line4", "pending"}, tw.Lines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+	if expected, result := int64(4), tw.TotalLines(); result != expected {
+		t.Errorf("expected %d lines, got %d", expected, result)
+	}
+	if expected, result := int64(32), tw.TotalBytesWritten(); result != expected {
+		t.Errorf("expected %d bytes, got %d", expected, result)
+	}
+}
+
+func BenchmarkTailBuffer_WriteByte(b *testing.B) {
+	tw := New(100)
+	data := []byte(strings.Repeat("x", 1000) + "\n")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = tw.WriteByte(data[i%len(data)])
+	}
+}
+
+func TestTailBuffer_WithResetOnMarker(t *testing.T) {
+	isMarker := func(line string) bool {
+		return line == "--- RUN START ---"
+	}
+
+	tests := []struct {
+		name       string
+		limit      int
+		keepMarker bool
+		input      string
+		expected   []string
+	}{
+		{
+			name:       "drop marker",
+			limit:      3,
+			keepMarker: false,
+			input:      "--- RUN START ---\na\nb\n--- RUN START ---\nc\n",
+			expected:   []string{"c"},
+		},
+		{
+			name:       "keep marker",
+			limit:      3,
+			keepMarker: true,
+			input:      "--- RUN START ---\na\nb\n--- RUN START ---\nc\n",
+			expected:   []string{"--- RUN START ---", "c"},
+		},
+		{
+			name:       "maxLines applies within a run",
+			limit:      3,
+			keepMarker: true,
+			input:      "a\n--- RUN START ---\nb\nc\nd\n",
+			expected:   []string{"b", "c", "d"},
+		},
+		{
+			name:       "marker as pending line",
+			limit:      3,
+			keepMarker: false,
+			input:      "a\nb\n--- RUN START ---",
+			expected:   []string{"a", "b", "--- RUN START ---"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(tt.limit, WithResetOnMarker(isMarker, tt.keepMarker))
+			if _, err := tw.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result := tw.Lines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_AtLineBoundary(t *testing.T) {
+	tests := []struct {
+		name     string
+		writes   []string
+		expected bool
+	}{
+		{
+			name:     "no writes",
+			writes:   nil,
+			expected: true,
+		},
+		{
+			name:     "ended with newline",
+			writes:   []string{"line1\nli", "ne2\n"},
+			expected: true,
+		},
+		{
+			name:     "mid-line",
+			writes:   []string{"line1\n", "li"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(3)
+			for _, data := range tt.writes {
+				if _, err := tw.Write([]byte(data)); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			if result := tw.AtLineBoundary(); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_WithDelimiterToken(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          string
+		input          string
+		expectedLines  []string
+		expectedString string
+	}{
+		{
+			name:           "crlf",
+			token:          "\r\n",
+			input:          "line1\r\nline2\rstill line2\nline3\r\n",
+			expectedLines:  []string{"line1", "line2\rstill line2\nline3"},
+			expectedString: "line1\r\nline2\rstill line2\nline3\r\n",
+		},
+		{
+			name:           "custom token",
+			token:          "<EOR>",
+			input:          "a<EOR>b<EO<EOR>c",
+			expectedLines:  []string{"a", "b<EO", "c"},
+			expectedString: "a<EOR>b<EO<EOR>c",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, mode := range []string{"whole", "write per byte", "WriteByte"} {
+			t.Run(tt.name+"/"+mode, func(t *testing.T) {
+				tw := New(5, WithDelimiterToken(tt.token))
+				switch mode {
+				case "whole":
+					if _, err := tw.Write([]byte(tt.input)); err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+				case "write per byte":
+					for i := range len(tt.input) {
+						if _, err := tw.Write([]byte(tt.input[i : i+1])); err != nil {
+							t.Fatalf("unexpected error: %v", err)
+						}
+					}
+				case "WriteByte":
+					for i := range len(tt.input) {
+						if err := tw.WriteByte(tt.input[i]); err != nil {
+							t.Fatalf("unexpected error: %v", err)
+						}
+					}
+				}
+
+				if result := tw.Lines(); !slices.Equal(result, tt.expectedLines) {
+					t.Errorf("Lines(): expected %q, got %q", tt.expectedLines, result)
+				}
+				if result := tw.String(); result != tt.expectedString {
+					t.Errorf("String(): expected %q, got %q", tt.expectedString, result)
+				}
+				if result := string(tw.RawBytes()); result != tt.expectedString {
+					t.Errorf("RawBytes(): expected %q, got %q", tt.expectedString, result)
+				}
+			})
+		}
+	}
+}
+
+func TestTailBuffer_CRLFSplitAcrossWrites(t *testing.T) {
+	tw := New(5)
+	for _, data := range []string{"line1\r", "\nline2\r", "\n"} {
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if expected, result := []string{"line1", "line2"}, tw.Lines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+	if expected, result := "line1\r\nline2\r\n", string(tw.RawBytes()); result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestTailBuffer_WithInitialBufferSize(t *testing.T) {
+	tw := New(100, WithInitialBufferSize(4096))
+	if result := tw.buffer.Cap(); result < 4096 {
+		t.Errorf("expected capacity of at least 4096, got %d", result)
+	}
+	if result := cap(tw.store); result != 100 {
+		t.Errorf("expected space for %d lines, got %d", 100, result)
+	}
+
+	data := strings.Repeat("a", 4000)
+	if _, err := tw.Write([]byte(data)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected, result := []string{data}, tw.Lines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %d bytes line, got %q", len(data), result)
+	}
+}
+
+func TestTailBuffer_WithClearOnFormFeed(t *testing.T) {
+	tests := []struct {
+		name     string
+		writes   []string
+		expected []string
+	}{
+		{
+			name:     "form feed on its own line",
+			writes:   []string{"line1\nline2\n\f\nline3\n"},
+			expected: []string{"", "line3"},
+		},
+		{
+			name:     "form feed in the middle of a line",
+			writes:   []string{"line1\nli", "ne2\fline3\nline4"},
+			expected: []string{"line3", "line4"},
+		},
+		{
+			name:     "form feed in the pending line",
+			writes:   []string{"line1\nline2\nab\fc"},
+			expected: []string{"c"},
+		},
+		{
+			name:     "multiple form feeds",
+			writes:   []string{"line1\n\fline2\n\fline3\n"},
+			expected: []string{"line3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, byByte := range []bool{false, true} {
+				tw := New(5, WithClearOnFormFeed())
+				for _, data := range tt.writes {
+					if byByte {
+						for i := range len(data) {
+							if err := tw.WriteByte(data[i]); err != nil {
+								t.Fatalf("unexpected error: %v", err)
+							}
+						}
+						continue
+					}
+					if _, err := tw.Write([]byte(data)); err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+				}
+
+				if result := tw.Lines(); !slices.Equal(result, tt.expected) {
+					t.Errorf("byByte=%v: expected %q, got %q", byByte, tt.expected, result)
+				}
+			}
+		})
+	}
+}
+
+func TestTailBuffer_WithSqueezeBlankLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		writes   []string
+		expected []string
+	}{
+		{
+			name:     "runs of blank lines",
+			writes:   []string{"line1\n\n\n\nline2\n\nline3\n"},
+			expected: []string{"line1", "", "line2", "", "line3"},
+		},
+		{
+			name:     "whitespace-only lines",
+			writes:   []string{"line1\n \n\t\n\nline2\n"},
+			expected: []string{"line1", " ", "line2"},
+		},
+		{
+			name:     "across writes",
+			writes:   []string{"line1\n\n", "\n", "\nline2\n"},
+			expected: []string{"line1", "", "line2"},
+		},
+		{
+			name:     "blank lines flood",
+			writes:   []string{"line1\n", strings.Repeat("\n", 100)},
+			expected: []string{"line1", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(5, WithSqueezeBlankLines())
+			for _, data := range tt.writes {
+				if _, err := tw.Write([]byte(data)); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			if result := tw.Lines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_NullSeparatedReader(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		input    string
+		expected string
+	}{
+		{
+			name:     "lines and pending line",
+			input:    "line1\nline2\nline3\nline4\npen",
+			expected: "line2\x00line3\x00line4\x00pen\x00",
+		},
+		{
+			name:     "paths with spaces",
+			input:    "/tmp/a b\n/tmp/c\r\n",
+			expected: "/tmp/a b\x00/tmp/c\x00",
+		},
+		{
+			name:     "independent of the delimiter",
+			opts:     []Option{WithDelimiters(';')},
+			input:    "a;b;",
+			expected: "a\x00b\x00",
+		},
+		{
+			name:     "empty",
+			input:    "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(4, tt.opts...)
+			if _, err := tw.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			r := tw.NullSeparatedReader()
+
+			// The reader is not affected by later writes
+			if _, err := tw.Write([]byte("more\n")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			b, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result := string(b); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_Close(t *testing.T) {
+	tw := New(3)
+	if _, err := tw.Write([]byte("line1\nline2\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := tw.Write([]byte("line3\n")); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected %v, got %v", ErrClosed, err)
+	}
+	if err := tw.WriteByte('\n'); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected %v, got %v", ErrClosed, err)
+	}
+	expected := []string{"line1", "line2"}
+	if result := tw.Lines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+	if err := tw.Close(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestTailBuffer_WithDrainOnClose(t *testing.T) {
+	ch := make(chan string)
+	tw := New(3, WithDrainOnClose(ch, 0))
+	if _, err := tw.Write([]byte("line1\nline2\nline3\nline4\npen")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- tw.Close()
+	}()
+	result := []string{}
+	for l := range ch {
+		result = append(result, l)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"line3", "line4", "pen"}
+	if !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+
+	// Lines are drained only once
+	if err := tw.Close(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestTailBuffer_WithDrainOnClose_Timeout(t *testing.T) {
+	ch := make(chan string, 1)
+	tw := New(3, WithDrainOnClose(ch, 10*time.Millisecond))
+	if _, err := tw.Write([]byte("line1\nline2\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Nobody receives, so only the line fitting in the channel is drained
+	if err := tw.Close(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	result := []string{}
+	for l := range ch {
+		result = append(result, l)
+	}
+	expected := []string{"line1"}
+	if !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestTailBuffer_WithClock(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	tw := New(3, WithClock(clock), WithTimestampPrefix(time.TimeOnly))
+	for _, data := range []string{"line1\n", "line2\nline3"} {
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expected := []string{"03:04:06 line1", "03:04:07 line2", "line3"}
+	if result := tw.Lines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestTailBuffer_WriteCounted(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		writes   []string
+		expected []int
+	}{
+		{
+			name:     "no eviction",
+			limit:    3,
+			writes:   []string{"line1\n", "line2\nline3\n"},
+			expected: []int{0, 0},
+		},
+		{
+			name:     "evicts previous lines",
+			limit:    2,
+			writes:   []string{"line1\nline2\n", "line3\n", "line4\nline5\n"},
+			expected: []int{0, 1, 2},
+		},
+		{
+			name:     "lines of the same write that do not fit",
+			limit:    2,
+			writes:   []string{"line1\nline2\nline3\nline4\n"},
+			expected: []int{2},
+		},
+		{
+			name:     "pending line is not evicted",
+			limit:    1,
+			writes:   []string{"line1\npen", "ding\n"},
+			expected: []int{0, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(tt.limit)
+			for i, data := range tt.writes {
+				n, evicted, err := tw.WriteCounted([]byte(data))
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if n != len(data) {
+					t.Errorf("expected %d bytes written, got %d", len(data), n)
+				}
+				if evicted != tt.expected[i] {
+					t.Errorf("write %d: expected %d evicted lines, got %d", i, tt.expected[i], evicted)
+				}
+			}
+		})
+	}
+}
+
+func TestNew_LargeMaxLines(t *testing.T) {
+	// Constructing a large buffer allocates no more than a small one, and no space for maxLines lines
+	small := testing.AllocsPerRun(10, func() { _ = New(1) })
+	large := testing.AllocsPerRun(10, func() { _ = New(100_000_000) })
+	if large > small {
+		t.Errorf("expected at most %v allocations, got %v", small, large)
+	}
+	if c := cap(New(100_000_000).store); c > initialLinesCap {
+		t.Errorf("expected space for at most %d lines, got %d", initialLinesCap, c)
+	}
+	if c := cap(New(100_000_000, WithInitialBufferSize(1)).store); c > initialLinesCap {
+		t.Errorf("expected space for at most %d lines, got %d", initialLinesCap, c)
+	}
+
+	// The cap is still enforced once the lines outgrow the initial space
+	tw := New(100)
+	for i := range 1000 {
+		if _, err := tw.Write([]byte(strconv.Itoa(i) + "\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	lines := tw.Lines()
+	if len(lines) != 100 || lines[0] != "900" || lines[99] != "999" {
+		t.Errorf("expected lines 900 to 999, got %d lines from %q", len(lines), lines[0])
+	}
+	// Lines completed by a write are evicted after all of them are appended, which may need more space
+	if c := cap(tw.store); c > 200 {
+		t.Errorf("expected backing array for at most %d lines, got %d", 200, c)
+	}
+}
+
+func TestTailBuffer_ReplaceInLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		old      string
+		new      string
+		expected []string
+	}{
+		{
+			name:     "strip stray carriage returns",
+			input:    "line1\r\r\nline2\rx\n",
+			old:      "\r",
+			new:      "",
+			expected: []string{"line1", "line2x"},
+		},
+		{
+			name:     "pending line is not affected",
+			input:    "a-b\nc-d",
+			old:      "-",
+			new:      "+",
+			expected: []string{"a+b", "c-d"},
+		},
+		{
+			name:     "no occurrences",
+			input:    "line1\n",
+			old:      "x",
+			new:      "y",
+			expected: []string{"line1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(3)
+			if _, err := tw.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tw.ReplaceInLines(tt.old, tt.new)
+
+			if result := tw.Lines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_WriteTo_TrailingNewline(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		input    string
+		expected string
+	}{
+		{
+			name:     "ended with newline",
+			input:    "line1\nline2\n",
+			expected: "line1\nline2\n",
+		},
+		{
+			name:     "ended mid-line",
+			input:    "line1\nline2",
+			expected: "line1\nline2",
+		},
+		{
+			name:     "ended with newline with WithAlwaysTrailingNewline",
+			opts:     []Option{WithAlwaysTrailingNewline()},
+			input:    "line1\nline2\n",
+			expected: "line1\nline2\n",
+		},
+		{
+			name:     "ended mid-line with WithAlwaysTrailingNewline",
+			opts:     []Option{WithAlwaysTrailingNewline()},
+			input:    "line1\nline2",
+			expected: "line1\nline2\n",
+		},
+		{
+			name:     "empty with WithAlwaysTrailingNewline",
+			opts:     []Option{WithAlwaysTrailingNewline()},
+			input:    "",
+			expected: "",
+		},
+		{
+			name:     "delimiter with WithAlwaysTrailingNewline",
+			opts:     []Option{WithAlwaysTrailingNewline(), WithDelimiters(';')},
+			input:    "a;b",
+			expected: "a;b;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(3, tt.opts...)
+			if _, err := tw.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if _, err := tw.WriteTo(&buf); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result := buf.String(); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_Grow(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		written  int
+		grow     int
+		expected int
+	}{
+		{
+			name:     "grows for more lines",
+			limit:    1000,
+			written:  10,
+			grow:     500,
+			expected: 510,
+		},
+		{
+			name:     "capped at maxLines",
+			limit:    100,
+			written:  10,
+			grow:     500,
+			expected: 100,
+		},
+		{
+			name:     "no-op when the space suffices",
+			limit:    1000,
+			written:  0,
+			grow:     10,
+			expected: initialLinesCap,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(tt.limit)
+			for i := range tt.written {
+				if _, err := tw.Write([]byte("line" + strconv.Itoa(i) + "\n")); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			tw.Grow(tt.grow)
+			if c := cap(tw.store); c != tt.expected {
+				t.Errorf("expected space for %d lines, got %d", tt.expected, c)
+			}
+			if result := tw.Len(); result != tt.written {
+				t.Errorf("expected %d lines, got %d", tt.written, result)
+			}
+
+			// Writing the burst does not reallocate
+			store := tw.store[:1]
+			for i := range min(tt.grow, tt.limit-tt.written) {
+				if _, err := tw.Write([]byte("burst" + strconv.Itoa(i) + "\n")); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if &tw.store[:1][0] != &store[0] {
+				t.Error("expected no reallocation")
+			}
+		})
+	}
+}
+
+// frameWriter records each write as a frame, failing after limit bytes if limit is not negative.
+type frameWriter struct {
+	frames []string
+	limit  int
+	short  bool
+}
+
+func (w *frameWriter) Write(p []byte) (int, error) {
+	if w.limit >= 0 && len(p) > w.limit {
+		if w.short {
+			w.frames = append(w.frames, string(p[:w.limit]))
+			return w.limit, nil
+		}
+		return 0, errors.New("write failed")
+	}
+	w.limit -= len(p)
+	w.frames = append(w.frames, string(p))
+	return len(p), nil
+}
+
+func TestTailBuffer_WriteToLines(t *testing.T) {
+	tests := []struct {
+		name           string
+		limit          int
+		short          bool
+		input          string
+		expectedFrames []string
+		expectedN      int64
+		expectedErr    bool
+	}{
+		{
+			name:           "one write per line",
+			limit:          -1,
+			input:          "line1\nline2\nline3\nline4\n",
+			expectedFrames: []string{"line2\n", "line3\n", "line4\n"},
+			expectedN:      18,
+		},
+		{
+			name:           "pending line",
+			limit:          -1,
+			input:          "line1\npen",
+			expectedFrames: []string{"line1\n", "pen\n"},
+			expectedN:      10,
+		},
+		{
+			name:           "error",
+			limit:          8,
+			input:          "line1\nline2\n",
+			expectedFrames: []string{"line1\n"},
+			expectedN:      6,
+			expectedErr:    true,
+		},
+		{
+			name:           "short write",
+			limit:          8,
+			short:          true,
+			input:          "line1\nline2\n",
+			expectedFrames: []string{"line1\n", "li"},
+			expectedN:      8,
+			expectedErr:    true,
+		},
+		{
+			name:           "empty",
+			limit:          -1,
+			input:          "",
+			expectedFrames: nil,
+			expectedN:      0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(3)
+			if _, err := tw.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			w := &frameWriter{limit: tt.limit, short: tt.short}
+			n, err := tw.WriteToLines(w)
+			if (err != nil) != tt.expectedErr {
+				t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if n != tt.expectedN {
+				t.Errorf("expected %d bytes written, got %d", tt.expectedN, n)
+			}
+			if !slices.Equal(w.frames, tt.expectedFrames) {
+				t.Errorf("expected %q, got %q", tt.expectedFrames, w.frames)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_LinesWithin(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	tests := []struct {
+		name       string
+		timestamps bool
+		d          time.Duration
+		expected   []string
+	}{
+		{"all", true, time.Hour, []string{"a", "b", "c", "d"}},
+		{"recent", true, time.Minute, []string{"c", "d"}},
+		{"pending only", true, 0, []string{"d"}},
+		{"without timestamps", false, time.Hour, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			opts := []Option{WithClock(clock)}
+			if tt.timestamps {
+				opts = append(opts, WithTimestamps())
+			}
+			tw := New(5, opts...)
+			for _, data := range []string{"a\n", "b\n", "c\n", "d"} {
+				if _, err := tw.Write([]byte(data)); err != nil {
+					t.Fatal(err)
+				}
+				now = now.Add(30 * time.Second)
+			}
+			if result := tw.LinesWithin(tt.d); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_IndexBy(t *testing.T) {
+	tw := New(5)
+	if _, err := tw.Write([]byte("req=4 start\nreq=1 start\nreq=2 start\nno request\nreq=1 done\nreq=3 pen")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The oldest line is evicted, and a line without a key is not indexed
+	result := tw.IndexBy(func(line string) string {
+		field, _, _ := strings.Cut(line, " ")
+		id, _ := strings.CutPrefix(field, "req=")
+		if id == field {
+			return ""
+		}
+		return id
+	})
+	expected := map[string]string{"1": "req=1 done", "2": "req=2 start", "3": "req=3 pen"}
+	if !maps.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestTailBuffer_Fields(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		sep      string
+		expected [][]string
+	}{
+		{
+			name:     "tab-separated",
+			input:    "a\tb\tc\nd\t\tf\n",
+			sep:      "\t",
+			expected: [][]string{{"a", "b", "c"}, {"d", "", "f"}},
+		},
+		{
+			name:     "whitespace",
+			input:    "PID  TTY   CMD\n  1  ?     init\npen",
+			sep:      "",
+			expected: [][]string{{"PID", "TTY", "CMD"}, {"1", "?", "init"}, {"pen"}},
+		},
+		{
+			name:     "multi-byte separator",
+			input:    "a::b\n",
+			sep:      "::",
+			expected: [][]string{{"a", "b"}},
+		},
+		{
+			name:     "empty",
+			input:    "",
+			sep:      "\t",
+			expected: [][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(3)
+			if _, err := tw.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result := tw.Fields(tt.sep)
+			if !slices.EqualFunc(result, tt.expected, slices.Equal) || result == nil {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_ForEachReverse(t *testing.T) {
+	tw := New(4)
+	if _, err := tw.Write([]byte("line1\nERROR a\nline3\nERROR b\nline5\npen")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("all lines", func(t *testing.T) {
+		var indexes []int
+		var lines []string
+		tw.ForEachReverse(func(i int, line string) bool {
+			indexes = append(indexes, i)
+			lines = append(lines, line)
+			return true
+		})
+		if expected := []int{3, 2, 1, 0}; !slices.Equal(indexes, expected) {
+			t.Errorf("expected %v, got %v", expected, indexes)
+		}
+		if expected := []string{"pen", "line5", "ERROR b", "line3"}; !slices.Equal(lines, expected) {
+			t.Errorf("expected %q, got %q", expected, lines)
+		}
+	})
+
+	t.Run("early stop", func(t *testing.T) {
+		calls := 0
+		found := ""
+		tw.ForEachReverse(func(i int, line string) bool {
+			calls++
+			if strings.HasPrefix(line, "ERROR") {
+				found = line
+				return false
+			}
+			return true
+		})
+		if found != "ERROR b" {
+			t.Errorf("expected %q, got %q", "ERROR b", found)
+		}
+		if calls != 3 {
+			t.Errorf("expected 3 calls, got %d", calls)
+		}
+	})
+}
+
+func TestTailBuffer_ContentHash(t *testing.T) {
+	write := func(t *testing.T, maxLines int, writes ...string) *TailBuffer {
+		t.Helper()
+		tw := New(maxLines)
+		for _, data := range writes {
+			if _, err := tw.Write([]byte(data)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		return tw
+	}
+
+	tests := []struct {
+		name  string
+		a     *TailBuffer
+		b     *TailBuffer
+		equal bool
+	}{
+		{
+			name:  "chunking does not matter",
+			a:     write(t, 3, "line1\nline2\n"),
+			b:     write(t, 3, "li", "ne1\nl", "ine2", "\n"),
+			equal: true,
+		},
+		{
+			name:  "evicted lines do not matter",
+			a:     write(t, 2, "line0\nline1\nline2\n"),
+			b:     write(t, 2, "line1\nline2\n"),
+			equal: true,
+		},
+		{
+			name:  "different lines",
+			a:     write(t, 3, "line1\nline2\n"),
+			b:     write(t, 3, "line1\nline3\n"),
+			equal: false,
+		},
+		{
+			name:  "pending line",
+			a:     write(t, 3, "line1\nline2\n"),
+			b:     write(t, 3, "line1\nline2"),
+			equal: false,
+		},
+		{
+			name:  "line boundaries",
+			a:     write(t, 3, "ab\nc\n"),
+			b:     write(t, 3, "a\nbc\n"),
+			equal: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.a.ContentHash() == tt.b.ContentHash(); result != tt.equal {
+				t.Errorf("expected %v, got %v", tt.equal, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_WithChangesOnly(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		opts     []Option
+		writes   []string
+		expected []string
+	}{
+		{
+			name:     "repeated readings",
+			limit:    5,
+			opts:     []Option{WithChangesOnly()},
+			writes:   []string{"20\n20\n21\n", "21\n21\n20\n"},
+			expected: []string{"20", "21", "20"},
+		},
+		{
+			name:     "across eviction",
+			limit:    1,
+			opts:     []Option{WithChangesOnly()},
+			writes:   []string{"a\nb\n", "b\n"},
+			expected: []string{"b"},
+		},
+		{
+			name:     "seen filter drops non-adjacent duplicates",
+			limit:    5,
+			opts:     []Option{WithSeenFilter(map[uint64]struct{}{})},
+			writes:   []string{"20\n20\n21\n", "21\n21\n20\n"},
+			expected: []string{"20", "21"},
+		},
+		{
+			name:     "pending line is not compared",
+			limit:    5,
+			opts:     []Option{WithChangesOnly()},
+			writes:   []string{"a\na"},
+			expected: []string{"a", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(tt.limit, tt.opts...)
+			for _, data := range tt.writes {
+				if _, err := tw.Write([]byte(data)); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			if result := tw.Lines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_WithMaxRunes(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		maxRunes int
+		writes   []string
+		expected []string
+	}{
+		{
+			name:     "multibyte lines",
+			limit:    10,
+			maxRunes: 5,
+			writes:   []string{"こんにちは\n世界\nです\n"},
+			expected: []string{"世界", "です"},
+		},
+		{
+			name:     "line cap is stricter",
+			limit:    1,
+			maxRunes: 100,
+			writes:   []string{"こんにちは\n世界\n"},
+			expected: []string{"世界"},
+		},
+		{
+			name:     "line longer than the cap is truncated",
+			limit:    10,
+			maxRunes: 3,
+			writes:   []string{"ab\n", "日本語のテキスト\n"},
+			expected: []string{"日本語"},
+		},
+		{
+			name:     "pending line is not counted",
+			limit:    10,
+			maxRunes: 4,
+			writes:   []string{"ab\ncd\nefghij"},
+			expected: []string{"ab", "cd", "efghij"},
+		},
+		{
+			name:     "truncated line followed by an empty line",
+			limit:    10,
+			maxRunes: 3,
+			writes:   []string{"ab\n日本語のテキスト\n\n"},
+			expected: []string{"日本語", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(tt.limit, WithMaxRunes(tt.maxRunes))
+			for _, data := range tt.writes {
+				if _, err := tw.Write([]byte(data)); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			if result := tw.Lines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+
+			// The lines do not depend on how the data is split into writes
+			bytewise := New(tt.limit, WithMaxRunes(tt.maxRunes))
+			for _, c := range []byte(strings.Join(tt.writes, "")) {
+				if err := bytewise.WriteByte(c); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if result := bytewise.Lines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+type shortWriter struct {
+	limit int
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	return min(len(p), w.limit), nil
+}
+
+func TestTailBuffer_WithPassthrough(t *testing.T) {
+	t.Run("partial lines are forwarded immediately", func(t *testing.T) {
+		var out bytes.Buffer
+		tw := New(2, WithPassthrough(&out), WithSqueezeBlankLines())
+		writes := []string{"line1\nli", "ne2\r\n", "\n\n", "line3"}
+		var expected string
+		for _, data := range writes {
+			if _, err := tw.Write([]byte(data)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected += data
+			if result := out.String(); result != expected {
+				t.Errorf("expected %q, got %q", expected, result)
+			}
+		}
+		if err := tw.WriteByte('!'); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected, result := expected+"!", out.String(); result != expected {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+		if expected, result := []string{"", "line3!"}, tw.Lines(); !slices.Equal(result, expected) {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	})
+
+	t.Run("short write", func(t *testing.T) {
+		tw := New(2, WithPassthrough(&shortWriter{limit: 4}))
+		n, err := tw.Write([]byte("line1\n"))
+		if !errors.Is(err, io.ErrShortWrite) {
+			t.Errorf("expected %v, got %v", io.ErrShortWrite, err)
+		}
+		if n != 4 {
+			t.Errorf("expected %d, got %d", 4, n)
+		}
+		if expected, result := []string{"line"}, tw.Lines(); !slices.Equal(result, expected) {
+			t.Errorf("expected %q, got %q", expected, result)
+		}
+	})
+}
+
+func TestTailBuffer_WithSampling(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		writes     []string
+		expected   []string
+		sampledOut int
+	}{
+		{
+			name:       "every nth line",
+			opts:       []Option{WithSampling(3)},
+			writes:     []string{"1\n2\n3\n4\n", "5\n6\n7\n8"},
+			expected:   []string{"1", "4", "7", "8"},
+			sampledOut: 4,
+		},
+		{
+			name:       "filtered lines are not sampled",
+			opts:       []Option{WithSampling(2), WithSqueezeBlankLines()},
+			writes:     []string{"1\n\n\n\n2\n3\n"},
+			expected:   []string{"1", "2"},
+			sampledOut: 2,
+		},
+		{
+			name:       "n of 1 keeps all lines",
+			opts:       []Option{WithSampling(1)},
+			writes:     []string{"1\n2\n"},
+			expected:   []string{"1", "2"},
+			sampledOut: 0,
+		},
+		{
+			name: "rate",
+			opts: []Option{WithSamplingRate(0.5, func() func() float64 {
+				values := []float64{0.1, 0.9, 0.4, 0.5}
+				return func() float64 {
+					v := values[0]
+					values = values[1:]
+					return v
+				}
+			}())},
+			writes:     []string{"1\n2\n3\n4\n"},
+			expected:   []string{"1", "3"},
+			sampledOut: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(10, tt.opts...)
+			for _, data := range tt.writes {
+				if _, err := tw.Write([]byte(data)); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if result := tw.Lines(); !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+			if result := tw.SampledOut(); result != tt.sampledOut {
+				t.Errorf("expected %d, got %d", tt.sampledOut, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_WithPendingFlushInterval(t *testing.T) {
+	var now atomic.Int64
+	clock := func() time.Time {
+		return time.Unix(0, now.Load())
+	}
+	advance := func(d time.Duration) {
+		now.Add(int64(d))
+	}
+	waitTotalLines := func(t *testing.T, tw *TailBuffer, expected int64) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for tw.TotalLines() != expected {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected %d lines completed, got %d", expected, tw.TotalLines())
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	const interval = time.Millisecond
+	tw := New(5, WithPendingFlushInterval(interval), WithClock(clock))
+	if _, err := tw.Write([]byte("line1\npart")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The pending line is kept while the clock does not advance
+	time.Sleep(10 * interval)
+	if result := tw.TotalLines(); result != 1 {
+		t.Errorf("expected %d, got %d", 1, result)
+	}
+
+	advance(interval)
+	waitTotalLines(t, tw, 2)
+	if _, err := tw.Write([]byte("next\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected, result := []string{"line1", "part", "next"}, tw.Lines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+
+	// Close stops flushing
+	if err := tw.WriteByte('x'); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	advance(interval)
+	time.Sleep(10 * interval)
+	if result := tw.TotalLines(); result != 3 {
+		t.Errorf("expected %d, got %d", 3, result)
+	}
+}
+
+func TestTailBuffer_WithKeepFirstLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     int
+		prepend   bool
+		writes    []string
+		reset     bool
+		firstLine string
+		expected  string
+		lines     []string
+	}{
+		{
+			name:      "no line completed",
+			limit:     2,
+			prepend:   true,
+			writes:    []string{"$ make"},
+			firstLine: "",
+			expected:  "$ make",
+			lines:     []string{"$ make"},
+		},
+		{
+			name:      "first line maintained",
+			limit:     2,
+			prepend:   true,
+			writes:    []string{"$ make\nok\n"},
+			firstLine: "$ make",
+			expected:  "$ make\nok\n",
+			lines:     []string{"$ make", "ok"},
+		},
+		{
+			name:      "first line evicted",
+			limit:     2,
+			prepend:   true,
+			writes:    []string{"$ make\nstep1\n", "step2\nstep3\npen"},
+			firstLine: "$ make",
+			expected:  "$ make\nstep3\npen",
+			lines:     []string{"$ make", "step3", "pen"},
+		},
+		{
+			name:      "reset",
+			limit:     2,
+			prepend:   true,
+			writes:    []string{"$ make\nstep1\n"},
+			reset:     true,
+			firstLine: "$ make",
+			expected:  "$ make\n",
+			lines:     []string{"$ make"},
+		},
+		{
+			name:      "not prepended",
+			limit:     2,
+			prepend:   false,
+			writes:    []string{"$ make\nstep1\n", "step2\nstep3\npen"},
+			firstLine: "$ make",
+			expected:  "step3\npen",
+			lines:     []string{"step3", "pen"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := New(tt.limit, WithKeepFirstLine(tt.prepend))
+			for _, data := range tt.writes {
+				if _, err := tw.Write([]byte(data)); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if tt.reset {
+				tw.Reset()
+			}
+
+			if result := tw.FirstLine(); result != tt.firstLine {
+				t.Errorf("expected %q, got %q", tt.firstLine, result)
+			}
+			if result := tw.String(); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+			if result := tw.Lines(); !slices.Equal(result, tt.lines) {
+				t.Errorf("expected %q, got %q", tt.lines, result)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_EachLine(t *testing.T) {
+	tw := New(3)
+	if _, err := tw.Write([]byte("line1\nline2\nline3\npen")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result []string
+	stop := errors.New("stop")
+	err := tw.EachLine(func(line string) error {
+		result = append(result, line)
+		if line == "line3" {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("expected %v, got %v", stop, err)
+	}
+	if expected := []string{"line2", "line3"}; !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestTailBuffer_ReadOutsideLock(t *testing.T) {
+	tw := New(3)
+	if _, err := tw.Write([]byte("line1\nline2\npen")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Lines are formatted after the lock is released
+	result := tw.ColorString(func(line string) Color {
+		if !tw.mu.TryLock() {
+			t.Error("expected the lock to be released")
+			return ColorNone
+		}
+		tw.mu.Unlock()
+		return ColorNone
+	})
+	if expected := "line1\nline2\npen"; result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+// BenchmarkTailBuffer_LockHold reports the time of String and Lines for a large window while a writer
+// contends for the lock, with the average and the longest time a Write of the writer takes, which is
+// dominated by the time the readers hold the lock.
+func BenchmarkTailBuffer_LockHold(b *testing.B) {
+	const maxLines = 100000
+	for _, bb := range []struct {
+		name string
+		read func(tw *TailBuffer)
+	}{
+		{name: "String", read: func(tw *TailBuffer) { _ = tw.String() }},
+		{name: "Lines", read: func(tw *TailBuffer) { _ = tw.Lines() }},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			tw := New(maxLines)
+			for i := range maxLines {
+				_, _ = tw.Write([]byte("This is line " + strconv.Itoa(i) + " of the window\n"))
+			}
+
+			var (
+				writes         int
+				total, longest time.Duration
+			)
+			stop := make(chan struct{})
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				data := []byte("This is a line written concurrently\n")
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+					start := time.Now()
+					_, _ = tw.Write(data)
+					took := time.Since(start)
+					writes++
+					total += took
+					longest = max(longest, took)
+				}
+			}()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				bb.read(tw)
+			}
+			b.StopTimer()
+			close(stop)
+			<-done
+			if writes > 0 {
+				b.ReportMetric(float64(total.Nanoseconds())/float64(writes), "write-ns")
+			}
+			b.ReportMetric(float64(longest.Nanoseconds()), "max-write-ns")
+		})
+	}
+}
+
+func TestTailBuffer_Freeze(t *testing.T) {
+	tw := FromLines(3, []string{"a", "b"})
+	if tw.Frozen() {
+		t.Error("expected not frozen")
+	}
+	tw.Freeze()
+	tw.Freeze()
+	if !tw.Frozen() {
+		t.Error("expected frozen")
+	}
+
+	if _, err := tw.Write([]byte("c\n")); !errors.Is(err, ErrFrozen) {
+		t.Errorf("expected %v, got %v", ErrFrozen, err)
+	}
+	if err := tw.WriteByte('c'); !errors.Is(err, ErrFrozen) {
+		t.Errorf("expected %v, got %v", ErrFrozen, err)
+	}
+	tw.Reset()
+	tw.ReplaceInLines("a", "x")
+	tw.SetMaxLines(1)
+	if s := tw.FlushSnapshotReset(); !slices.Equal(s.Lines, []string{"a", "b"}) {
+		t.Errorf("expected %q, got %q", []string{"a", "b"}, s.Lines)
+	}
+
+	expected := []string{"a", "b"}
+	if result := tw.Lines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+	if result := tw.String(); result != "a\nb\n" {
+		t.Errorf("expected %q, got %q", "a\nb\n", result)
+	}
+}
@@ -0,0 +1,33 @@
+package tail
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// throttledWriter is an io.Writer that waits between writes to a TailBuffer.
+type throttledWriter struct {
+	tb      *TailBuffer
+	d       time.Duration
+	mu      sync.Mutex
+	started bool
+}
+
+// ThrottledWriter returns an io.Writer that writes to tb, sleeping d between writes.
+// It is intended for simulating slow producers in tests of consumers such as Follower.
+func (tb *TailBuffer) ThrottledWriter(d time.Duration) io.Writer {
+	return &throttledWriter{tb: tb, d: d}
+}
+
+// Write implements the io.Writer interface.
+func (w *throttledWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.started {
+		time.Sleep(w.d)
+	}
+	w.started = true
+	return w.tb.Write(p)
+}
@@ -0,0 +1,30 @@
+package tail
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTailBuffer_ThrottledWriter(t *testing.T) {
+	tw := New(3)
+	w := tw.ThrottledWriter(10 * time.Millisecond)
+
+	start := time.Now()
+	for _, data := range []string{"line1\n", "line2\n", "line3\n"} {
+		n, err := w.Write([]byte(data))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != len(data) {
+			t.Errorf("expected %d bytes written, got %d", len(data), n)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("expected at least 20ms, got %v", elapsed)
+	}
+
+	if expected, result := []string{"line1", "line2", "line3"}, tw.Lines(); !slices.Equal(result, expected) {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
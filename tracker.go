@@ -0,0 +1,113 @@
+package tail
+
+import (
+	"cmp"
+	"runtime"
+	"slices"
+)
+
+// WriterStat is the number of writes from a call site, recorded by WithWriteTracker.
+type WriterStat struct {
+	// Function is the fully qualified name of the function that wrote.
+	Function string
+	File     string
+	Line     int
+	Writes   int64
+}
+
+// writeTracker counts writes by the program counter of their call sites.
+type writeTracker struct {
+	writes map[uintptr]int64
+	// maxCallSites and warn are set by WithWriterLimit.
+	maxCallSites int
+	warn         func(stats []WriterStat)
+	// warnings is the stats to be passed to warn once the lock is released.
+	warnings [][]WriterStat
+}
+
+// tracker returns the write tracker, creating it if it does not exist yet.
+func (tb *TailBuffer) tracker() *writeTracker {
+	if tb.writeTracker == nil {
+		tb.writeTracker = &writeTracker{writes: map[uintptr]int64{}}
+	}
+	return tb.writeTracker
+}
+
+// callerPC returns the program counter of the caller of the write method, skip frames above callerPC.
+// It returns 0 if WithWriteTracker is not set.
+func (tb *TailBuffer) callerPC(skip int) uintptr {
+	if tb.writeTracker == nil {
+		return 0
+	}
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return 0
+	}
+	return pcs[0]
+}
+
+// trackWrite records a write from pc, and queues a warning if pc is a new call site beyond the limit set by WithWriterLimit.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) trackWrite(pc uintptr) {
+	t := tb.writeTracker
+	if t == nil {
+		return
+	}
+	t.writes[pc]++
+	if t.warn != nil && t.writes[pc] == 1 && len(t.writes) > t.maxCallSites {
+		t.warnings = append(t.warnings, tb.writerStats())
+	}
+}
+
+// warnWriters calls the function set by WithWriterLimit with the queued warnings.
+// It must be called without tb.mu held.
+func (tb *TailBuffer) warnWriters() {
+	t := tb.writeTracker
+	if t == nil || t.warn == nil {
+		return
+	}
+	tb.mu.Lock()
+	warnings := t.warnings
+	t.warnings = nil
+	tb.mu.Unlock()
+
+	for _, stats := range warnings {
+		t.warn(stats)
+	}
+}
+
+// WriterStats returns the call sites that have written with the number of writes from each, the most writes first,
+// e.g. to find code paths writing to a buffer meant for a single source.
+// It returns nil if WithWriteTracker is not set.
+func (tb *TailBuffer) WriterStats() []WriterStat {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if tb.writeTracker == nil {
+		return nil
+	}
+	return tb.writerStats()
+}
+
+// writerStats returns the call sites as WriterStats does.
+// It must be called with tb.mu held.
+func (tb *TailBuffer) writerStats() []WriterStat {
+	result := make([]WriterStat, 0, len(tb.writeTracker.writes))
+	for pc, writes := range tb.writeTracker.writes {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		result = append(result, WriterStat{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+			Writes:   writes,
+		})
+	}
+	slices.SortFunc(result, func(a, b WriterStat) int {
+		return cmp.Or(
+			cmp.Compare(b.Writes, a.Writes),
+			cmp.Compare(a.File, b.File),
+			cmp.Compare(a.Line, b.Line),
+		)
+	})
+	return result
+}
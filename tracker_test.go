@@ -0,0 +1,91 @@
+package tail
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestTailBuffer_WriterStats(t *testing.T) {
+	tw := New(3, WithWriteTracker())
+	_, _, line, _ := runtime.Caller(0)
+	for range 3 {
+		if _, err := tw.Write([]byte("a\n")); err != nil { // line + 2
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := tw.WriteByte('b'); err != nil { // line + 6
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := tw.WriteCounted([]byte("c\n")); err != nil { // line + 9
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stats := tw.WriterStats()
+	expected := []struct {
+		line   int
+		writes int64
+	}{
+		{line + 2, 3},
+		{line + 6, 1},
+		{line + 9, 1},
+	}
+	if len(stats) != len(expected) {
+		t.Fatalf("expected %d call sites, got %+v", len(expected), stats)
+	}
+	for i, e := range expected {
+		s := stats[i]
+		if s.Line != e.line || s.Writes != e.writes {
+			t.Errorf("expected %d writes at line %d, got %d at line %d", e.writes, e.line, s.Writes, s.Line)
+		}
+		if !strings.HasSuffix(s.File, "tracker_test.go") || !strings.HasSuffix(s.Function, "TestTailBuffer_WriterStats") {
+			t.Errorf("unexpected call site %s in %s", s.Function, s.File)
+		}
+	}
+
+	if result := New(3).WriterStats(); result != nil {
+		t.Errorf("expected nil, got %+v", result)
+	}
+}
+
+func TestTailBuffer_WithWriterLimit(t *testing.T) {
+	var warnings [][]WriterStat
+	var tw *TailBuffer
+	tw = New(3, WithWriterLimit(1, func(stats []WriterStat) {
+		// The buffer can be read from warn
+		if result := tw.WriterStats(); len(result) != len(stats) {
+			t.Errorf("expected %d call sites, got %d", len(stats), len(result))
+		}
+		warnings = append(warnings, stats)
+	}))
+	write := func(data string) {
+		t.Helper()
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	for range 2 {
+		write("a\n")
+	}
+	if len(warnings) != 0 {
+		t.Fatalf("expected no warnings, got %+v", warnings)
+	}
+	for range 2 {
+		if err := tw.WriteByte('b'); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("expected %d warning, got %+v", 1, warnings)
+	}
+	if stats := warnings[0]; len(stats) != 2 || stats[0].Writes != 2 || stats[1].Writes != 1 {
+		t.Errorf("expected 2 call sites with 2 and 1 writes, got %+v", stats)
+	}
+	if _, _, err := tw.WriteCounted([]byte("c\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 2 || len(warnings[1]) != 3 {
+		t.Errorf("expected a warning with 3 call sites, got %+v", warnings)
+	}
+}